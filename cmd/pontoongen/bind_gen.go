@@ -0,0 +1,644 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+// bindGen generates reflection-free RegisterHTTPHandlers
+// for a service. Generated bindings follow the rules
+// of sdesc's reflective binder.
+type bindGen struct {
+	pkg *types.Package
+	buf *bytes.Buffer
+
+	// imports maps import paths to package names.
+	imports map[string]string
+}
+
+var typeTextUnmarshaler = func() *types.Interface {
+	sig := types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewVar(0, nil, "", types.NewSlice(types.Typ[types.Byte]))),
+		types.NewTuple(types.NewVar(0, nil, "", types.Universe.Lookup("error").Type())),
+		false)
+	m := types.NewFunc(0, nil, "UnmarshalText", sig)
+	return types.NewInterfaceType([]*types.Func{m}, nil).Complete()
+}()
+
+// genBindings generates RegisterHTTPHandlers for the service
+// and returns it with the imports it needs.
+func genBindings(s serviceDesc, pkg *types.Package) (string, []string, error) {
+	g := &bindGen{
+		pkg:     pkg,
+		buf:     bytes.NewBuffer(nil),
+		imports: map[string]string{},
+	}
+	for _, h := range s.handlers {
+		// the bindings would silently serve the route without them
+		switch {
+		case h.route.middlewares:
+			return "", nil, errors.Errorf("'%v %v' has route or group middlewares, they can't be generated; use ServiceOptions or sdesc.WithGlobalMiddlewares", h.httpVerb, h.path)
+		case h.route.dynamicTimeout:
+			return "", nil, errors.Errorf("'%v %v' has a non-constant timeout, it can't be generated", h.httpVerb, h.path)
+		case h.route.optsErr != nil:
+			return "", nil, errors.Wrapf(h.route.optsErr, "'%v %v' has options that can't be generated", h.httpVerb, h.path)
+		}
+	}
+
+	http := g.use("net/http", "http")
+	sdesc := g.use(descPkgName, "sdesc")
+
+	g.p("// RegisterHTTPHandlers registers %v's endpoints in the mux", s.serviceStructName)
+	g.p("// using generated bindings instead of reflection.")
+	g.p("func (s %v) RegisterHTTPHandlers(mux *%v.ServeMux, opts ...%v.RouterOption) {", s.serviceStructName, http, sdesc)
+	g.p("%v.RegisterCompiled(mux, &s, []%v.CompiledRoute{", sdesc, sdesc)
+	for _, h := range s.handlers {
+		g.genRoute(h)
+	}
+	g.p("}, opts...)")
+	g.p("}")
+
+	done := map[string]bool{}
+	for _, h := range s.handlers {
+		if done[h.goFuncName] {
+			continue
+		}
+		done[h.goFuncName] = true
+
+		err := g.genServe(s, h)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "handler '%v'", h.goFuncName)
+		}
+	}
+
+	return g.buf.String(), g.importSpecs(), nil
+}
+
+// importSpecs returns the import specs, standard library first.
+func (g *bindGen) importSpecs() []string {
+	var std, other []string
+	for p, name := range g.imports {
+		spec := strconv.Quote(p)
+		if name != path.Base(p) {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			other = append(other, spec)
+			continue
+		}
+		std = append(std, spec)
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	if len(std) > 0 && len(other) > 0 {
+		std = append(std, "")
+	}
+	return append(std, other...)
+}
+
+func (g *bindGen) p(format string, args ...interface{}) {
+	fmt.Fprintf(g.buf, format+"\n", args...)
+}
+
+// use imports the package and returns its name.
+func (g *bindGen) use(path, name string) string {
+	g.imports[path] = name
+	return name
+}
+
+func (g *bindGen) qualifier(p *types.Package) string {
+	if p == g.pkg {
+		return ""
+	}
+	return g.use(p.Path(), p.Name())
+}
+
+func (g *bindGen) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func serveFuncName(h hdlDesc) string {
+	return "pontoonServe" + strcase.ToCamel(h.goFuncName)
+}
+
+func (g *bindGen) genRoute(h hdlDesc) {
+	sdesc := g.use(descPkgName, "sdesc")
+
+	opID := spec.OperationID(h.httpVerb, h.path)
+	if h.route.operationID != "" {
+		opID = h.route.operationID
+	}
+
+	g.p("{")
+	g.p("Method: %q,", h.httpVerb)
+	g.p("Pattern: %q,", h.path)
+	g.p("OperationID: %q,", opID)
+	if len(h.route.tags) > 0 {
+		g.p("Tags: %v,", stringsLit(h.route.tags))
+	}
+	if h.route.deprecated {
+		g.p("Deprecated: true,")
+	}
+	if len(h.route.security) > 0 {
+		g.p("Security: []%v.SecurityRequirement{", sdesc)
+		for _, sec := range h.route.security {
+			g.p("{Scheme: %q, Scopes: %v},", sec.scheme, stringsLit(sec.scopes))
+		}
+		g.p("},")
+	}
+	if h.route.noSecurity {
+		g.p("NoSecurity: true,")
+	}
+	if h.inout.socket != nil {
+		g.p("WebSocket: true,")
+	}
+	if len(h.route.origins) > 0 {
+		g.p("Origins: %v,", stringsLit(h.route.origins))
+	}
+	if h.route.timeout > 0 {
+		g.p("Timeout: %v, // %v", h.route.timeout, time.Duration(h.route.timeout))
+	}
+	if h.inout.sig.Results().Len() == 2 && !h.inout.hasResponseWriter {
+		if h.inout.stream {
+			g.p("Stream: true,")
+		} else {
+			g.p("Output: true,")
+		}
+	}
+	g.p("Serve: s.%v,", serveFuncName(h))
+	g.p("},")
+}
+
+func stringsLit(ss []string) string {
+	if len(ss) == 0 {
+		return "nil"
+	}
+	q := make([]string, len(ss))
+	for i, s := range ss {
+		q[i] = strconv.Quote(s)
+	}
+	return "[]string{" + strings.Join(q, ", ") + "}"
+}
+
+// genServe generates a method that binds the handler's input,
+// calls the handler and writes its result.
+func (g *bindGen) genServe(s serviceDesc, h hdlDesc) error {
+	http := g.use("net/http", "http")
+	sdesc := g.use(descPkgName, "sdesc")
+	sig := h.inout.sig
+
+	args := []string{}
+	var bind *bytes.Buffer
+	var inType types.Type
+	var hasForm bool
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		switch t.String() {
+		case "*net/http.Request":
+			args = append(args, "r")
+			continue
+		case "net/http.ResponseWriter":
+			args = append(args, "w")
+			continue
+		case "context.Context":
+			if h.inout.socket != nil {
+				args = append(args, "ctx")
+			} else {
+				args = append(args, sdesc+".CallContext(w, r)")
+			}
+			continue
+		}
+		if ch, ok := t.Underlying().(*types.Chan); ok {
+			// WebSocket's messages
+			if ch.Dir() == types.RecvOnly {
+				args = append(args, "recv")
+			} else {
+				args = append(args, "send")
+			}
+			continue
+		}
+
+		inType = t
+		arg := "in"
+		if p, ok := t.(*types.Pointer); ok {
+			inType = p.Elem()
+			arg = "&in"
+		}
+		if _, ok := inType.Underlying().(*types.Struct); !ok {
+			return errors.Errorf("input type '%v' should be a struct or a pointer to struct", t)
+		}
+		args = append(args, arg)
+
+		bind = bytes.NewBuffer(nil)
+		bg := &bindGen{pkg: g.pkg, buf: bind, imports: g.imports}
+		var err error
+		hasForm, err = bg.bindStruct(inType, "in")
+		if err != nil {
+			return errors.Wrapf(err, "input type '%v'", t)
+		}
+	}
+
+	g.p("")
+	g.p("func (s %v) %v(w %v.ResponseWriter, r *%v.Request) error {", s.serviceStructName, serveFuncName(h), http, http)
+	if bind != nil {
+		g.p("var in %v", g.typeString(inType))
+		g.p("bd := %v.NewBinding(r, %v)", sdesc, hasForm)
+		g.buf.Write(bind.Bytes())
+		g.p("if err := bd.Err(); err != nil {")
+		g.p("return err")
+		g.p("}")
+		g.p("")
+	}
+
+	call := fmt.Sprintf("s.%v(%v)", h.goFuncName, strings.Join(args, ", "))
+	if sd := h.inout.socket; sd != nil {
+		n := sig.Params().Len()
+		client := sig.Params().At(n - 2).Type().Underlying().(*types.Chan).Elem()
+		server := sig.Params().At(n - 1).Type().Underlying().(*types.Chan).Elem()
+		g.p("%v.ServeWebSocket(w, r, func(ctx %v.Context, recv <-chan %v, send chan<- %v) error {",
+			sdesc, g.use("context", "context"), g.typeString(client), g.typeString(server))
+		g.p("return %v", call)
+		g.p("})")
+		g.p("return nil")
+		g.p("}")
+		return nil
+	}
+	if sig.Results().Len() == 1 {
+		g.p("if err := %v; err != nil {", call)
+		g.p("return err")
+		g.p("}")
+		if !h.inout.hasResponseWriter {
+			g.p("w.WriteHeader(%v.StatusOK)", http)
+		}
+		g.p("return nil")
+		g.p("}")
+		return nil
+	}
+
+	g.p("out, err := %v", call)
+	g.p("if err != nil {")
+	g.p("return err")
+	g.p("}")
+	switch {
+	case h.inout.hasResponseWriter:
+	case h.inout.stream:
+		elem := sig.Results().At(0).Type().Underlying().(*types.Chan).Elem()
+		g.p("%v.WriteStream[%v](w, r, out)", sdesc, g.typeString(elem))
+	default:
+		g.p("%v.WriteResult(w, r, out)", sdesc)
+	}
+	g.p("return nil")
+	g.p("}")
+	return nil
+}
+
+// bindStruct generates the bindings of the struct's fields.
+// It mirrors sdesc's binder.addStruct.
+func (g *bindGen) bindStruct(t types.Type, path string) (hasForm bool, err error) {
+	st := t.Underlying().(*types.Struct)
+
+	if !hasInLocations(st) {
+		// whole struct is a JSON body
+		name := path
+		if n, ok := t.(*types.Named); ok {
+			name = n.Obj().Name()
+		}
+		return false, g.bindBody(t, path, name, spec.In{Location: spec.LocBody, Name: "json"})
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		fpath := path + "." + f.Name()
+
+		if f.Embedded() {
+			ft := f.Type()
+			ptr, isPtr := ft.(*types.Pointer)
+			if isPtr {
+				ft = ptr.Elem()
+			}
+			if _, ok := ft.Underlying().(*types.Struct); !ok {
+				continue
+			}
+
+			sub := &bindGen{pkg: g.pkg, buf: bytes.NewBuffer(nil), imports: g.imports}
+			subForm, err := sub.bindStruct(ft, fpath)
+			if err != nil {
+				return false, errors.Wrapf(err, "embedded field '%v'", f.Name())
+			}
+			if isPtr && sub.buf.Len() > 0 {
+				g.p("if %v == nil {", fpath)
+				g.p("%v = new(%v)", fpath, g.typeString(ft))
+				g.p("}")
+			}
+			g.buf.Write(sub.buf.Bytes())
+			hasForm = hasForm || subForm
+			continue
+		}
+
+		in := spec.ParseIn(reflect.StructTag(st.Tag(i)).Get("in"))
+		if in == nil || in.Location == "" {
+			continue
+		}
+		if !f.Exported() {
+			return false, errors.Errorf("field '%v' has an `in` tag but is not exported", f.Name())
+		}
+
+		switch in.Location {
+		case spec.LocBody:
+			if in.Name != "json" {
+				return false, errors.Errorf("field '%v': unsupported body format '%v'", f.Name(), in.Name)
+			}
+			err = g.bindBody(f.Type(), fpath, f.Name(), *in)
+		case spec.LocForm:
+			hasForm = true
+			err = g.bindValue(f, fpath, *in)
+		default:
+			err = g.bindValue(f, fpath, *in)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return hasForm, nil
+}
+
+// hasInLocations mirrors sdesc's hasInLocations.
+func hasInLocations(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		in := spec.ParseIn(reflect.StructTag(st.Tag(i)).Get("in"))
+		if in != nil && in.Location != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// bindValue binds a query, header, path or form value.
+func (g *bindGen) bindValue(f *types.Var, fpath string, in spec.In) error {
+	fieldArgs := fmt.Sprintf("%q, %q, %q", f.Name(), in.Location, in.Name)
+	fail := fmt.Sprintf("bd.Fail(%v, %%v.Error())", fieldArgs)
+
+	if in.Location == spec.LocForm {
+		switch f.Type().String() {
+		case "mime/multipart.File":
+			g.p("if v, ok := bd.FormFile(%q, %q, %v); ok {", f.Name(), in.Name, in.Required)
+			g.p("%v = v", fpath)
+			g.p("}")
+			return nil
+		case "*mime/multipart.FileHeader":
+			g.p("if v, ok := bd.FormFileHeader(%q, %q, %v); ok {", f.Name(), in.Name, in.Required)
+			g.p("%v = v", fpath)
+			g.p("}")
+			return nil
+		}
+	}
+
+	t := f.Type()
+	if sl, ok := t.Underlying().(*types.Slice); ok && !isBytes(sl) {
+		if !isScalarType(sl.Elem()) {
+			return errors.Errorf("field '%v': cannot read type '%v' from %v", f.Name(), sl.Elem(), in.Location)
+		}
+		g.p("if vs, ok := bd.Values(%v, %v, %q); ok {", fieldArgs, in.Required, in.Default)
+		g.p("%v = make(%v, len(vs))", fpath, g.typeString(t))
+		g.p("for i, v := range vs {")
+		err := g.conv(sl.Elem(), fpath+"[i]", "v", fail)
+		if err != nil {
+			return err
+		}
+		g.p("}")
+		g.p("}")
+		return nil
+	}
+
+	if !isScalarType(t) {
+		return errors.Errorf("field '%v': cannot read type '%v' from %v", f.Name(), t, in.Location)
+	}
+	g.p("if v, ok := bd.Value(%v, %v, %q); ok {", fieldArgs, in.Required, in.Default)
+	err := g.conv(t, fpath, "v", fail)
+	if err != nil {
+		return err
+	}
+	g.p("}")
+	return nil
+}
+
+// bindBody decodes the JSON body and applies the directives
+// of its fields, mirroring sdesc's fieldBinder.bindBody.
+func (g *bindGen) bindBody(t types.Type, fpath, goName string, in spec.In) error {
+	bt := t
+	ptr, isPtr := t.(*types.Pointer)
+	if isPtr {
+		bt = ptr.Elem()
+	}
+
+	var dirs []bodyDirective
+	if st, ok := bt.Underlying().(*types.Struct); ok {
+		dirs = jsonDirectives(st, fpath)
+	}
+
+	if len(dirs) == 0 {
+		g.p("bd.Body(%q, %v, &%v)", goName, in.Required, fpath)
+		return nil
+	}
+
+	if isPtr {
+		g.p("if keys, ok := bd.Body(%q, %v, &%v); ok && %v != nil {", goName, in.Required, fpath, fpath)
+	} else {
+		g.p("if keys, ok := bd.Body(%q, %v, &%v); ok {", goName, in.Required, fpath)
+	}
+	for _, d := range dirs {
+		g.p("if _, found := keys[%q]; !found {", d.name)
+		if d.in.Default != "" {
+			fail := fmt.Sprintf("bd.Fail(%q, %q, %q, %%v.Error())", d.goName, spec.LocBody, d.name)
+			err := g.conv(d.t, d.path, strconv.Quote(d.in.Default), fail)
+			if err != nil {
+				return errors.Wrapf(err, "field '%v'", d.goName)
+			}
+		} else {
+			g.p("bd.Missing(%q, %q, %q)", d.goName, spec.LocBody, d.name)
+		}
+		g.p("}")
+	}
+	g.p("}")
+	return nil
+}
+
+type bodyDirective struct {
+	path   string
+	goName string
+	name   string
+	t      types.Type
+	in     spec.In
+}
+
+// jsonDirectives mirrors sdesc's jsonDirectives.
+func jsonDirectives(st *types.Struct, path string) []bodyDirective {
+	var ret []bodyDirective
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		fpath := path + "." + f.Name()
+		jsonTag := reflect.StructTag(st.Tag(i)).Get("json")
+
+		if f.Embedded() && jsonTag == "" {
+			if sub, ok := f.Type().Underlying().(*types.Struct); ok {
+				if _, isPtr := f.Type().(*types.Pointer); !isPtr {
+					ret = append(ret, jsonDirectives(sub, fpath)...)
+					continue
+				}
+			}
+		}
+
+		in := spec.ParseIn(reflect.StructTag(st.Tag(i)).Get("in"))
+		if in == nil || in.Location != "" || (!in.Required && in.Default == "") {
+			continue
+		}
+		name := spec.JSONName(f.Name(), jsonTag)
+		if name == "-" || !f.Exported() {
+			continue
+		}
+		ret = append(ret, bodyDirective{
+			path:   fpath,
+			goName: f.Name(),
+			name:   name,
+			t:      f.Type(),
+			in:     *in,
+		})
+	}
+	return ret
+}
+
+func isBytes(sl *types.Slice) bool {
+	b, ok := sl.Elem().Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+func isTime(t types.Type) bool {
+	return t.String() == "time.Time"
+}
+
+func isTextUnmarshaler(t types.Type) bool {
+	return types.Implements(types.NewPointer(t), typeTextUnmarshaler)
+}
+
+// isScalarType mirrors sdesc's isScalar.
+func isScalarType(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if isTime(t) || isTextUnmarshaler(t) {
+		return true
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Kind() == types.Uintptr || u.Info()&types.IsUntyped != 0 {
+			return false
+		}
+		return u.Info()&(types.IsString|types.IsBoolean|types.IsInteger|types.IsFloat) != 0
+	case *types.Slice:
+		return isBytes(u)
+	}
+	return false
+}
+
+// conv generates the code that parses the string expression src
+// into dst; fail is a statement format that reports the error.
+// It mirrors sdesc's setScalar.
+func (g *bindGen) conv(t types.Type, dst, src, fail string) error {
+	if p, ok := t.(*types.Pointer); ok {
+		g.p("%v = new(%v)", dst, g.typeString(p.Elem()))
+		return g.conv(p.Elem(), "*"+dst, src, fail)
+	}
+
+	if isTime(t) {
+		tm := g.use("time", "time")
+		g.p("if x, err := %v.Parse(%v.RFC3339, %v); err != nil {", tm, tm, src)
+		g.p(fail, "err")
+		g.p("} else {")
+		g.p("%v = x", dst)
+		g.p("}")
+		return nil
+	}
+	if isTextUnmarshaler(t) {
+		recv := dst
+		if strings.HasPrefix(recv, "*") {
+			recv = "(" + recv + ")"
+		}
+		g.p("if err := %v.UnmarshalText([]byte(%v)); err != nil {", recv, src)
+		g.p(fail, "err")
+		g.p("}")
+		return nil
+	}
+
+	ts := g.typeString(t)
+	convert := func(x string, same types.BasicKind) string {
+		if b, ok := t.(*types.Basic); ok && b.Kind() == same {
+			return x
+		}
+		return ts + "(" + x + ")"
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if isBytes(u) {
+			g.p("%v = %v(%v)", dst, ts, src)
+			return nil
+		}
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			g.p("%v = %v", dst, convert(src, types.String))
+			return nil
+		case info&types.IsBoolean != 0:
+			g.parse(fmt.Sprintf("strconv.ParseBool(%v)", src), dst, convert("x", types.Bool), fail)
+			return nil
+		case info&types.IsInteger != 0 && info&types.IsUnsigned != 0:
+			g.parse(fmt.Sprintf("strconv.ParseUint(%v, 10, %v)", src, basicBits(u)), dst, convert("x", types.Uint64), fail)
+			return nil
+		case info&types.IsInteger != 0:
+			g.parse(fmt.Sprintf("strconv.ParseInt(%v, 10, %v)", src, basicBits(u)), dst, convert("x", types.Int64), fail)
+			return nil
+		case info&types.IsFloat != 0:
+			g.parse(fmt.Sprintf("strconv.ParseFloat(%v, %v)", src, basicBits(u)), dst, convert("x", types.Float64), fail)
+			return nil
+		}
+	}
+	return errors.Errorf("cannot convert a string to '%v'", t)
+}
+
+// parse generates a strconv call that sets dst on success.
+func (g *bindGen) parse(call, dst, val, fail string) {
+	g.use("strconv", "strconv")
+	g.p("if x, err := %v; err != nil {", call)
+	g.p(fail, "err")
+	g.p("} else {")
+	g.p("%v = %v", dst, val)
+	g.p("}")
+}
+
+// basicBits returns the bit size of the number type;
+// 0 stands for int and uint.
+func basicBits(b *types.Basic) int {
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	case types.Int64, types.Uint64, types.Float64:
+		return 64
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// loadTestService describes the only service of the package in dir.
+func loadTestService(t *testing.T, dir string) (serviceDesc, pkgServices) {
+	t.Helper()
+	ps := loadServices(dir, false)
+	if len(ps) != 1 || len(ps[0].svcs) != 1 {
+		t.Fatalf("got %v packages, want a single service", len(ps))
+	}
+	return ps[0].svcs[0], ps[0]
+}
+
+func TestGenBindingsRefusesUnresolvedOptions(t *testing.T) {
+	svc, ps := loadTestService(t, "testdata/routeopts")
+
+	for _, h := range svc.handlers {
+		if (h.route.optsErr == nil) != (h.path == "/direct") {
+			t.Errorf("'%v': got options error %v", h.path, h.route.optsErr)
+		}
+
+		one := svc
+		one.handlers = []hdlDesc{h}
+		code, _, err := genBindings(one, ps.pkg.Types)
+		if h.path != "/direct" {
+			if err == nil {
+				t.Errorf("'%v': bindings are generated without its security", h.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("'%v': %v", h.path, err)
+		}
+		if !strings.Contains(code, `{Scheme: "jwt", Scopes: nil}`) {
+			t.Errorf("'%v': bindings miss the security requirement:\n%v", h.path, code)
+		}
+	}
+
+	if _, _, err := genBindings(svc, ps.pkg.Types); err == nil {
+		t.Error("bindings are generated for the service")
+	}
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+var typeTextMarshaler = func() *types.Interface {
+	sig := types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(
+			types.NewVar(0, nil, "", types.NewSlice(types.Typ[types.Byte])),
+			types.NewVar(0, nil, "", types.Universe.Lookup("error").Type())),
+		false)
+	m := types.NewFunc(0, nil, "MarshalText", sig)
+	return types.NewInterfaceType([]*types.Func{m}, nil).Complete()
+}()
+
+// genClient generates a typed client of the service
+// and returns it with the imports it needs.
+// Requests are serialised following the rules of sdesc's binder.
+func genClient(s serviceDesc, pkg *types.Package) (string, []string, error) {
+	g := &bindGen{
+		pkg:     pkg,
+		buf:     bytes.NewBuffer(nil),
+		imports: map[string]string{},
+	}
+	sdesc := g.use(descPkgName, "sdesc")
+	name := s.serviceStructName + "Client"
+
+	g.p("// %v calls %v's endpoints over HTTP.", name, s.serviceStructName)
+	g.p("// Zero values of the optional parameters aren't sent, leaving them")
+	g.p("// to the defaults; use pointer fields to send them explicitly.")
+	g.p("type %v struct {", name)
+	g.p("c *%v.Client", sdesc)
+	g.p("}")
+	g.p("")
+	g.p("// New%v creates a client of %v served at baseURL.", name, s.serviceStructName)
+	g.p("func New%v(baseURL string, opts ...%v.ClientOption) (*%v, error) {", name, sdesc, name)
+	g.p("c, err := %v.NewClient(baseURL, opts...)", sdesc)
+	g.p("if err != nil {")
+	g.p("return nil, err")
+	g.p("}")
+	g.p("return &%v{c: c}, nil", name)
+	g.p("}")
+
+	// handlers registered more than once are named by their operations
+	uses := map[string]int{}
+	for _, h := range s.handlers {
+		uses[h.goFuncName]++
+	}
+	for _, h := range s.handlers {
+		if h.inout.socket != nil {
+			continue
+		}
+		method := strcase.ToCamel(h.goFuncName)
+		if uses[h.goFuncName] > 1 {
+			method = strcase.ToCamel(handlerOperationID(h))
+		}
+		err := g.genCall(s, h, name, method)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "handler '%v'", h.goFuncName)
+		}
+	}
+	return g.buf.String(), g.importSpecs(), nil
+}
+
+func handlerOperationID(h hdlDesc) string {
+	if h.route.operationID != "" {
+		return h.route.operationID
+	}
+	return spec.OperationID(h.httpVerb, h.path)
+}
+
+// genCall generates a client's method calling the handler.
+func (g *bindGen) genCall(s serviceDesc, h hdlDesc, client, method string) error {
+	sdesc := g.use(descPkgName, "sdesc")
+	ctx := g.use("context", "context")
+	sig := h.inout.sig
+
+	var inType types.Type
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		switch t.String() {
+		case "*net/http.Request", "net/http.ResponseWriter", "context.Context":
+			continue
+		}
+		inType = t
+	}
+
+	params := ctx + ".Context"
+	if inType != nil {
+		params = "ctx " + params + ", in " + g.typeString(inType)
+	} else {
+		params = "ctx " + params
+	}
+
+	var outType types.Type
+	var result string
+	switch {
+	case h.inout.hasResponseWriter:
+		result = "(*" + g.use("net/http", "http") + ".Response, error)"
+	case h.inout.stream:
+		outType = sig.Results().At(0).Type()
+		elem := outType.Underlying().(*types.Chan).Elem()
+		result = "(*" + sdesc + ".Stream[" + g.typeString(elem) + "], error)"
+	case sig.Results().Len() == 2:
+		outType = sig.Results().At(0).Type()
+		result = "(" + g.typeString(outType) + ", error)"
+	default:
+		result = "error"
+	}
+
+	g.p("")
+	g.p("// %v calls %v %v.", method, h.httpVerb, h.path)
+	if h.inout.hasResponseWriter {
+		g.p("// The caller should close the response's body.")
+	}
+	if h.inout.stream {
+		g.p("// The stream's channel is closed once it ends or ctx is done,")
+		g.p("// check its Err then.")
+	}
+	g.p("// Failed calls return *%v.ClientError.", sdesc)
+	for _, r := range errorBodies(s, h) {
+		name := r.t.typeName
+		if p, n, ok := strings.Cut(name, "."); ok && p == g.pkg.Name() {
+			name = n
+		}
+		g.p("// A %v response's body decodes into %v via its DecodeBody.", r.code, name)
+	}
+	g.p("func (c *%v) %v(%v) %v {", client, method, params, result)
+	g.p("req := c.c.NewRequest(%q, %q)", h.httpVerb, h.path)
+
+	if inType != nil {
+		st := inType
+		p, isPtr := inType.(*types.Pointer)
+		if isPtr {
+			st = p.Elem()
+			g.p("if in != nil {")
+		}
+		if err := g.serializeStruct(st, "in"); err != nil {
+			return errors.Wrapf(err, "input type '%v'", inType)
+		}
+		if isPtr {
+			g.p("}")
+		}
+	}
+
+	switch {
+	case h.inout.hasResponseWriter:
+		g.p("return c.c.DoRaw(ctx, req)")
+	case h.inout.stream:
+		elem := outType.Underlying().(*types.Chan).Elem()
+		g.p("return %v.DoStream[%v](ctx, c.c, req)", sdesc, g.typeString(elem))
+	case outType != nil:
+		g.p("var out %v", g.typeString(outType))
+		g.p("if err := c.c.Do(ctx, req, &out); err != nil {")
+		g.p("return out, err")
+		g.p("}")
+		g.p("return out, nil")
+	default:
+		g.p("return c.c.Do(ctx, req, nil)")
+	}
+	g.p("}")
+	return nil
+}
+
+// errorBodies lists the handler's responses with custom bodies
+// in order of their codes; handler's responses override service-wide ones.
+func errorBodies(s serviceDesc, h hdlDesc) []respDesc {
+	ret := []respDesc{}
+	seen := map[int]bool{}
+	for _, r := range append(append([]respDesc{}, h.inout.responses...), s.responses...) {
+		if r.t == nil || seen[r.code] {
+			continue
+		}
+		seen[r.code] = true
+		ret = append(ret, r)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].code < ret[j].code
+	})
+	return ret
+}
+
+// serializeStruct generates the code that puts the struct's fields
+// into the request. It mirrors bindStruct.
+func (g *bindGen) serializeStruct(t types.Type, path string) error {
+	st := t.Underlying().(*types.Struct)
+
+	if !hasInLocations(st) {
+		// whole struct is a JSON body
+		g.p("req.Body(%v)", path)
+		return nil
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		fpath := path + "." + f.Name()
+
+		if f.Embedded() {
+			ft := f.Type()
+			ptr, isPtr := ft.(*types.Pointer)
+			if isPtr {
+				ft = ptr.Elem()
+			}
+			if _, ok := ft.Underlying().(*types.Struct); !ok {
+				continue
+			}
+
+			sub := &bindGen{pkg: g.pkg, buf: bytes.NewBuffer(nil), imports: g.imports}
+			err := sub.serializeStruct(ft, fpath)
+			if err != nil {
+				return errors.Wrapf(err, "embedded field '%v'", f.Name())
+			}
+			if sub.buf.Len() == 0 {
+				continue
+			}
+			if isPtr {
+				g.p("if %v != nil {", fpath)
+			}
+			g.buf.Write(sub.buf.Bytes())
+			if isPtr {
+				g.p("}")
+			}
+			continue
+		}
+
+		in := spec.ParseIn(reflect.StructTag(st.Tag(i)).Get("in"))
+		if in == nil || in.Location == "" {
+			continue
+		}
+		if !f.Exported() {
+			return errors.Errorf("field '%v' has an `in` tag but is not exported", f.Name())
+		}
+
+		var err error
+		switch in.Location {
+		case spec.LocBody:
+			if in.Name != "json" {
+				return errors.Errorf("field '%v': unsupported body format '%v'", f.Name(), in.Name)
+			}
+			if _, ok := f.Type().(*types.Pointer); ok {
+				g.p("if %v != nil {", fpath)
+				g.p("req.Body(%v)", fpath)
+				g.p("}")
+				continue
+			}
+			g.p("req.Body(%v)", fpath)
+		default:
+			err = g.serializeValue(f, fpath, *in)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serializeValue puts a query, header, path or form value into the request.
+func (g *bindGen) serializeValue(f *types.Var, fpath string, in spec.In) error {
+	put := map[string]string{
+		spec.LocQuery:  "Query",
+		spec.LocHeader: "Header",
+		spec.LocPath:   "Path",
+		spec.LocForm:   "Form",
+	}[in.Location]
+	if put == "" {
+		return errors.Errorf("field '%v': unknown location '%v'", f.Name(), in.Location)
+	}
+
+	if in.Location == spec.LocForm {
+		switch f.Type().String() {
+		case "mime/multipart.File":
+			g.p("req.Multipart()")
+			g.p("if %v != nil {", fpath)
+			g.p("req.FormFile(%q, %q, %v)", in.Name, in.Name, fpath)
+			g.p("}")
+			return nil
+		case "*mime/multipart.FileHeader":
+			g.p("req.Multipart()")
+			g.p("if %v != nil {", fpath)
+			g.p("req.FormFileHeader(%q, %v)", in.Name, fpath)
+			g.p("}")
+			return nil
+		}
+	}
+
+	t := f.Type()
+	if sl, ok := t.Underlying().(*types.Slice); ok && !isBytes(sl) {
+		if in.Location == spec.LocPath {
+			return errors.Errorf("field '%v': path parameter cannot be a slice", f.Name())
+		}
+		v, err := g.format(sl.Elem(), "v")
+		if err != nil {
+			return errors.Wrapf(err, "field '%v'", f.Name())
+		}
+		g.p("for _, v := range %v {", fpath)
+		g.p("req.%v(%q, %v)", put, in.Name, v)
+		g.p("}")
+		return nil
+	}
+
+	v, err := g.format(t, fpath)
+	if err != nil {
+		return errors.Wrapf(err, "field '%v'", f.Name())
+	}
+	// zero values of the optional fields are left to the Service's
+	// defaults, like unset pointers; only the required ones are
+	// always sent, so the Service reports them as missing
+	cond := ""
+	if _, ok := t.(*types.Pointer); ok {
+		cond = fpath + " != nil"
+	} else if in.Location != spec.LocPath && !in.Required {
+		cond = nonZero(t, fpath)
+	}
+	if cond == "" {
+		g.p("req.%v(%q, %v)", put, in.Name, v)
+		return nil
+	}
+	g.p("if %v {", cond)
+	g.p("req.%v(%q, %v)", put, in.Name, v)
+	g.p("}")
+	return nil
+}
+
+// nonZero returns the condition that's true if the value isn't zero,
+// empty if the value should always be sent.
+func nonZero(t types.Type, v string) string {
+	if isTime(t) {
+		return "!" + v + ".IsZero()"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		return "len(" + v + ") > 0"
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			return v + ` != ""`
+		case info&types.IsBoolean != 0:
+			return v
+		case info&types.IsNumeric != 0:
+			return v + " != 0"
+		}
+	}
+	return ""
+}
+
+func isTextMarshaler(t types.Type) bool {
+	return types.Implements(t, typeTextMarshaler) ||
+		types.Implements(types.NewPointer(t), typeTextMarshaler)
+}
+
+// format returns the expression formatting the value v of type t
+// as a string. It mirrors conv.
+func (g *bindGen) format(t types.Type, v string) (string, error) {
+	p, isPtr := t.(*types.Pointer)
+	if isPtr {
+		if !isTime(p.Elem()) && !isTextUnmarshaler(p.Elem()) {
+			return g.format(p.Elem(), "*"+v)
+		}
+		// the methods are called via the pointer
+		t = p.Elem()
+	}
+
+	if isTime(t) {
+		tm := g.use("time", "time")
+		return fmt.Sprintf("%v.Format(%v.RFC3339)", v, tm), nil
+	}
+	if isTextUnmarshaler(t) {
+		if !isTextMarshaler(t) {
+			return "", errors.Errorf("type '%v' cannot be formatted as it doesn't implement encoding.TextMarshaler", t)
+		}
+		if !isPtr && !types.Implements(t, typeTextMarshaler) {
+			v = "&" + v
+		}
+		return fmt.Sprintf("req.MarshalText(%v)", v), nil
+	}
+
+	convert := func(to types.BasicKind, name string) string {
+		if b, ok := t.(*types.Basic); ok && b.Kind() == to {
+			return v
+		}
+		return name + "(" + v + ")"
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if isBytes(u) {
+			return "string(" + v + ")", nil
+		}
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			return convert(types.String, "string"), nil
+		case info&types.IsBoolean != 0:
+			g.use("strconv", "strconv")
+			return fmt.Sprintf("strconv.FormatBool(%v)", convert(types.Bool, "bool")), nil
+		case info&types.IsInteger != 0 && info&types.IsUnsigned != 0:
+			g.use("strconv", "strconv")
+			return fmt.Sprintf("strconv.FormatUint(%v, 10)", convert(types.Uint64, "uint64")), nil
+		case info&types.IsInteger != 0:
+			g.use("strconv", "strconv")
+			return fmt.Sprintf("strconv.FormatInt(%v, 10)", convert(types.Int64, "int64")), nil
+		case info&types.IsFloat != 0:
+			g.use("strconv", "strconv")
+			return fmt.Sprintf("strconv.FormatFloat(%v, 'g', -1, %v)", convert(types.Float64, "float64"), basicBits(u)), nil
+		}
+	}
+	return "", errors.Errorf("cannot format '%v' as a string", t)
+}
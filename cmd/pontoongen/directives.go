@@ -0,0 +1,106 @@
+package main
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const directivePrefix = "pontoon:"
+
+// directive is a line of a doc comment that configures pontoongen, i.e.
+//
+//	// pontoon:response 404 NotFoundError
+type directive struct {
+	name string
+	args []string
+}
+
+// parseDoc splits a doc comment into the text
+// and pontoon directives.
+func parseDoc(cg *ast.CommentGroup) (string, []directive) {
+	if cg == nil {
+		return "", nil
+	}
+
+	var dirs []directive
+	for _, c := range cg.List {
+		line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if !strings.HasPrefix(line, directivePrefix) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, directivePrefix))
+		if len(fields) == 0 {
+			continue
+		}
+		dirs = append(dirs, directive{name: fields[0], args: fields[1:]})
+	}
+
+	// CommentGroup.Text drops //pontoon: lines, but keeps '// pontoon:' ones
+	lines := strings.Split(cg.Text(), "\n")
+	text := lines[:0]
+	for _, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), directivePrefix) {
+			continue
+		}
+		text = append(text, l)
+	}
+	return strings.Join(text, "\n"), dirs
+}
+
+// getResponses converts 'response' directives to response descriptions.
+// Directive's format is 'response <code> [<type>]', where type is
+// a type from the service's package or an imported one ('pkg.Type').
+// Responses without a type use the default error schema.
+func (b builder) getResponses(dirs []directive) ([]respDesc, error) {
+	ret := []respDesc{}
+	for _, d := range dirs {
+		if d.name != "response" {
+			continue
+		}
+		if len(d.args) == 0 || len(d.args) > 2 {
+			return nil, errors.Errorf("expected 'response <code> [<type>]', got '%v'", strings.Join(d.args, " "))
+		}
+		code, err := strconv.Atoi(d.args[0])
+		if err != nil || code < 100 || code > 599 {
+			return nil, errors.Errorf("bad HTTP status code '%v' in a response directive", d.args[0])
+		}
+
+		rd := respDesc{code: code}
+		if len(d.args) == 2 {
+			t, err := b.lookupType(d.args[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "response %v", code)
+			}
+			rd.t, err = b.rootStructDesc(t)
+			if err != nil {
+				return nil, errors.Wrapf(err, "converting response type '%v' to type description", d.args[1])
+			}
+		}
+		ret = append(ret, rd)
+	}
+	return ret, nil
+}
+
+// lookupType finds a type by its name in the service's package
+// or in one of its imports ('pkg.Type').
+func (b builder) lookupType(name string) (types.Type, error) {
+	scope := b.pkg.Types.Scope()
+	if pkgName, typeName, ok := strings.Cut(name, "."); ok {
+		pkg := findImportedPackage(b.pkg.Types, pkgName)
+		if pkg == nil {
+			return nil, errors.Errorf("package '%v' is not imported", pkgName)
+		}
+		scope = pkg.Scope()
+		name = typeName
+	}
+
+	obj, ok := scope.Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, errors.Errorf("type '%v' not found", name)
+	}
+	return obj.Type(), nil
+}
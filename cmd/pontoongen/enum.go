@@ -0,0 +1,148 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// getEnumDesc describes a named scalar; the exported constants
+// of the type declared together in a const block of its package
+// become the values of its enum. Blocks of a single constant,
+// i.e. a default value, and types of other modules, e.g. time.Duration,
+// aren't enums.
+func (b *builder) getEnumDesc(t *types.Named, tu *types.Basic) (*typeDesc, error) {
+	base, err := b.getTypeDescCached(tu)
+	if err != nil {
+		return nil, err
+	}
+	if t.Obj().Pkg() == nil || !b.inModule(t.Obj().Pkg().Path()) {
+		return base, nil
+	}
+
+	blocks := map[*ast.GenDecl][]*types.Const{}
+	scope := t.Obj().Pkg().Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !c.Exported() || !types.Identical(c.Type(), t) {
+			continue
+		}
+		_, gd := b.constSpec(c)
+		if gd == nil || !gd.Lparen.IsValid() {
+			continue
+		}
+		blocks[gd] = append(blocks[gd], c)
+	}
+	var consts []*types.Const
+	for _, cs := range blocks {
+		if len(cs) > 1 {
+			consts = append(consts, cs...)
+		}
+	}
+	if len(consts) == 0 {
+		return base, nil
+	}
+	// the values follow their declarations
+	sort.Slice(consts, func(i, j int) bool {
+		return consts[i].Pos() < consts[j].Pos()
+	})
+
+	ret := *base
+	seen := map[interface{}]bool{}
+	for _, c := range consts {
+		v := constValue(c.Val())
+		if v == nil || seen[v] {
+			// aliases of the values aren't repeated
+			continue
+		}
+		seen[v] = true
+		ret.enum = append(ret.enum, descEnum{
+			name:  c.Name(),
+			doc:   b.constDoc(c),
+			value: v,
+		})
+	}
+	return &ret, nil
+}
+
+// inModule is true if the package was loaded with the syntax
+// and belongs to the module being generated.
+func (b *builder) inModule(path string) bool {
+	pkg := b.findPackage(path)
+	if pkg == nil || len(pkg.Syntax) == 0 {
+		return false
+	}
+	return pkg.Module != nil && b.pkg.Module != nil &&
+		pkg.Module.Path == b.pkg.Module.Path
+}
+
+// constValue converts the constant to the value of its JSON.
+func constValue(v constant.Value) interface{} {
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v)
+	case constant.Bool:
+		return constant.BoolVal(v)
+	case constant.Int:
+		if i, ok := constant.Int64Val(v); ok {
+			return i
+		}
+		if u, ok := constant.Uint64Val(v); ok {
+			return u
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f
+	}
+	return nil
+}
+
+// constDoc returns the doc comment of the constant; the trailing
+// line comment is used if there's none.
+func (b *builder) constDoc(c *types.Const) string {
+	vs, gd := b.constSpec(c)
+	if vs == nil {
+		return ""
+	}
+	doc := vs.Doc
+	if doc == nil && !gd.Lparen.IsValid() {
+		// const Foo T = ...
+		doc = gd.Doc
+	}
+	if doc == nil {
+		doc = vs.Comment
+	}
+	if doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// constSpec returns the declaration of the constant
+// and its const block, nils if it's not loaded.
+func (b *builder) constSpec(c *types.Const) (*ast.ValueSpec, *ast.GenDecl) {
+	pkg := b.findPackage(c.Pkg().Path())
+	if pkg == nil {
+		return nil, nil
+	}
+	f, err := astFindFile(pkg, c.Pos())
+	if err != nil {
+		return nil, nil
+	}
+	path, _ := astutil.PathEnclosingInterval(f, c.Pos(), c.Pos())
+	for i, n := range path {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if gd, ok := path[i+1].(*ast.GenDecl); ok {
+			return vs, gd
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// maxCallDepth limits how deep errCodes follows the calls
+// when looking for returned errors.
+// sdesc's helpers like sdesc.NotFound take one level.
+const maxCallDepth = 3
+
+// errCodes infers HTTP status codes of the errors returned by a handler
+// by looking at its return statements.
+// It recognizes:
+//   - sdesc.NewError, sdesc.Errorf, sdesc.WithStatus and sdesc.Error{Code: ...}
+//     with constant codes, and sdesc helpers such as sdesc.NotFound;
+//   - package-level sentinel errors initialized with any of the above;
+//   - values of types whose StatusCode() method returns a constant;
+//   - errors wrapped via fmt.Errorf or errors packages;
+//   - calls to functions and methods that return any of the above;
+//     only the ones of the module being generated and sdesc's are followed.
+type errCodes struct {
+	b builder
+
+	// inProgress guards against recursive calls
+	inProgress map[types.Object]bool
+}
+
+// fnScope is a body of a function being analyzed.
+type fnScope struct {
+	pkg *packages.Package
+	// assigns holds expressions assigned to the local variables.
+	assigns map[types.Object][]ast.Expr
+}
+
+func newFnScope(pkg *packages.Package, body ast.Node) fnScope {
+	ret := fnScope{
+		pkg:     pkg,
+		assigns: map[types.Object][]ast.Expr{},
+	}
+	if body == nil {
+		return ret
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		as, ok := n.(*ast.AssignStmt)
+		if !ok {
+			return true
+		}
+		for i, l := range as.Lhs {
+			id, ok := l.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := pkg.TypesInfo.ObjectOf(id)
+			if obj == nil {
+				continue
+			}
+			switch {
+			case len(as.Rhs) == len(as.Lhs):
+				ret.assigns[obj] = append(ret.assigns[obj], as.Rhs[i])
+			case len(as.Rhs) == 1 && i == len(as.Lhs)-1:
+				// a, err := f()
+				ret.assigns[obj] = append(ret.assigns[obj], as.Rhs[0])
+			}
+		}
+		return true
+	})
+	return ret
+}
+
+func (b builder) inferErrorCodes(fn *types.Func) []int {
+	ec := errCodes{
+		b:          b,
+		inProgress: map[types.Object]bool{},
+	}
+	found := map[int]bool{}
+	ec.funcCodes(fn, 0, found)
+
+	ret := []int{}
+	for code := range found {
+		if code >= 400 && code <= 599 {
+			ret = append(ret, code)
+		}
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// funcCodes collects codes of the errors returned by fn;
+// the error is expected to be its last result.
+func (ec errCodes) funcCodes(fn *types.Func, depth int, found map[int]bool) {
+	if depth > maxCallDepth || ec.inProgress[fn] {
+		return
+	}
+	pkg, fd := ec.funcDecl(fn)
+	if fd == nil || fd.Body == nil {
+		return
+	}
+	ec.inProgress[fn] = true
+	defer delete(ec.inProgress, fn)
+
+	nres := fn.Type().(*types.Signature).Results().Len()
+	sc := newFnScope(pkg, fd.Body)
+
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		switch rs := n.(type) {
+		case *ast.FuncLit:
+			// returns of closures are not ours
+			return false
+		case *ast.ReturnStmt:
+			if len(rs.Results) == 0 {
+				return false
+			}
+			if len(rs.Results) == nres {
+				ec.exprCodes(sc, rs.Results[nres-1], depth, found)
+				return false
+			}
+			// return f()
+			ec.exprCodes(sc, rs.Results[0], depth, found)
+			return false
+		}
+		return true
+	})
+}
+
+func (ec errCodes) exprCodes(sc fnScope, ex ast.Expr, depth int, found map[int]bool) {
+	info := sc.pkg.TypesInfo
+
+	// concrete types can carry their codes
+	if t := info.TypeOf(ex); t != nil && !types.IsInterface(t) {
+		ec.typeCodes(t, found)
+	}
+
+	switch e := astutil.Unparen(ex).(type) {
+	case *ast.Ident:
+		obj := info.Uses[e]
+		if as, ok := sc.assigns[obj]; ok && !ec.inProgress[obj] {
+			ec.inProgress[obj] = true
+			for _, a := range as {
+				ec.exprCodes(sc, a, depth, found)
+			}
+			delete(ec.inProgress, obj)
+			return
+		}
+		ec.varCodes(obj, depth, found)
+	case *ast.SelectorExpr:
+		ec.varCodes(info.Uses[e.Sel], depth, found)
+	case *ast.UnaryExpr:
+		ec.exprCodes(sc, e.X, depth, found)
+	case *ast.CompositeLit:
+		if t := info.TypeOf(e); t != nil && isDescType(t, "Error") {
+			for _, el := range e.Elts {
+				kv, ok := el.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if k, ok := kv.Key.(*ast.Ident); ok && k.Name == "Code" {
+					addConst(info, kv.Value, found)
+				}
+			}
+		}
+	case *ast.CallExpr:
+		switch callee := typeutil.Callee(info, e).(type) {
+		case *types.Builtin:
+			if callee.Name() == "new" && len(e.Args) == 1 {
+				if t := info.TypeOf(e.Args[0]); t != nil {
+					ec.typeCodes(t, found)
+				}
+			}
+		case *types.Func:
+			ec.callCodes(sc, e, callee, depth, found)
+		}
+	}
+}
+
+func (ec errCodes) callCodes(sc fnScope, call *ast.CallExpr, fn *types.Func, depth int, found map[int]bool) {
+	info := sc.pkg.TypesInfo
+
+	if fn.Pkg() == nil {
+		return
+	}
+	switch fn.Pkg().Path() {
+	case descPkgName:
+		switch fn.Name() {
+		case "NewError", "Errorf":
+			if len(call.Args) > 0 {
+				addConst(info, call.Args[0], found)
+			}
+			return
+		case "WithStatus":
+			if len(call.Args) > 1 {
+				addConst(info, call.Args[1], found)
+			}
+			return
+		}
+	case "fmt", "errors", "github.com/pkg/errors":
+		// wrapped errors keep their codes
+		for _, a := range call.Args {
+			ec.exprCodes(sc, a, depth, found)
+		}
+		return
+	}
+
+	// errors of the dependencies are rarely returned as is,
+	// and following their calls would take the whole module graph
+	if fn.Pkg().Path() != descPkgName && !ec.b.inModule(fn.Pkg().Path()) {
+		return
+	}
+	ec.funcCodes(fn, depth+1, found)
+}
+
+// varCodes follows package-level sentinel errors to their initializers.
+func (ec errCodes) varCodes(obj types.Object, depth int, found map[int]bool) {
+	v, ok := obj.(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return
+	}
+	if ec.inProgress[v] {
+		return
+	}
+	pkg := ec.b.findPackage(v.Pkg().Path())
+	if pkg == nil || pkg.TypesInfo == nil {
+		return
+	}
+	f, err := astFindFile(pkg, v.Pos())
+	if err != nil {
+		return
+	}
+	path, _ := astutil.PathEnclosingInterval(f, v.Pos(), v.Pos())
+	for _, n := range path {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for i, name := range vs.Names {
+			if name.Pos() != v.Pos() || i >= len(vs.Values) {
+				continue
+			}
+			ec.inProgress[v] = true
+			ec.exprCodes(newFnScope(pkg, nil), vs.Values[i], depth, found)
+			delete(ec.inProgress, v)
+		}
+		return
+	}
+}
+
+// typeCodes finds a code of the type with a StatusCode() method.
+func (ec errCodes) typeCodes(t types.Type, found map[int]bool) {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true, nil, "StatusCode")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return
+	}
+	pkg, fd := ec.funcDecl(fn)
+	if fd == nil || fd.Body == nil {
+		return
+	}
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if rs, ok := n.(*ast.ReturnStmt); ok && len(rs.Results) == 1 {
+			addConst(pkg.TypesInfo, rs.Results[0], found)
+		}
+		return true
+	})
+}
+
+// funcDecl finds a declaration of the func; it returns nil
+// if the func's package was loaded without the syntax.
+func (ec errCodes) funcDecl(fn *types.Func) (*packages.Package, *ast.FuncDecl) {
+	if fn.Pkg() == nil {
+		return nil, nil
+	}
+	pkg := ec.b.findPackage(fn.Pkg().Path())
+	if pkg == nil || pkg.TypesInfo == nil {
+		return nil, nil
+	}
+	f, err := astFindFile(pkg, fn.Pos())
+	if err != nil {
+		return nil, nil
+	}
+	path, _ := astutil.PathEnclosingInterval(f, fn.Pos(), fn.Pos())
+	for _, n := range path {
+		if fd, ok := n.(*ast.FuncDecl); ok && fd.Name.Pos() == fn.Pos() {
+			return pkg, fd
+		}
+	}
+	return nil, nil
+}
+
+func addConst(info *types.Info, ex ast.Expr, found map[int]bool) {
+	tv, ok := info.Types[ex]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return
+	}
+	if v, ok := constant.Int64Val(tv.Value); ok {
+		found[int(v)] = true
+	}
+}
+
+func isDescType(t types.Type, name string) bool {
+	n, ok := t.(*types.Named)
+	return ok &&
+		n.Obj().Pkg() != nil &&
+		n.Obj().Pkg().Path() == descPkgName &&
+		n.Obj().Name() == name
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferErrorCodesSkipsDependencies(t *testing.T) {
+	// errdeps is a module of its own, with a dependency
+	t.Setenv("GOWORK", "off")
+	svc, _ := loadTestService(t, "testdata/errdeps")
+
+	if len(svc.handlers) != 1 {
+		t.Fatalf("got %v handlers, want 1", len(svc.handlers))
+	}
+	var got []int
+	for _, r := range svc.handlers[0].inout.responses {
+		got = append(got, r.code)
+	}
+	// dep.Lock's 409 isn't followed, the module's find's 404 is
+	if want := []int{404}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got codes %v, want %v", got, want)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"golang.org/x/tools/go/ast/astutil"
 )
 
-func (b builder) getHandleDesc(fnIdent *ast.Ident, ms *types.MethodSet) (*hdlTypesDesc, error) {
+// getHandleDesc describes the handler method;
+// sig overrides the method's signature if it's not nil.
+func (b builder) getHandleDesc(fnIdent *ast.Ident, ms *types.MethodSet, sig *types.Signature) (*hdlTypesDesc, error) {
 	var sel *types.Selection
 
 	for i := 0; i < ms.Len(); i++ {
@@ -24,12 +26,15 @@ func (b builder) getHandleDesc(fnIdent *ast.Ident, ms *types.MethodSet) (*hdlTyp
 
 	f, _ := b.astFindFile(sel.Obj().Pos())
 
-	sig := sel.Type().(*types.Signature)
+	if sig == nil {
+		sig = sel.Type().(*types.Signature)
+	}
 
 	var inType *typeDesc
 	var outType *typeDesc
 
-	var hasResponseWriter bool
+	var hasResponseWriter, stream bool
+	var socket *socketDesc
 	for i := 0; i < sig.Params().Len(); i++ {
 		p := sig.Params().At(i)
 		namedType := p.Type()
@@ -40,6 +45,32 @@ func (b builder) getHandleDesc(fnIdent *ast.Ident, ms *types.MethodSet) (*hdlTyp
 			hasResponseWriter = true
 			continue
 		}
+		if namedType.String() == "context.Context" {
+			if i != 0 {
+				// sdesc refuses such handlers
+				return nil, errors.New("context.Context should be the first parameter")
+			}
+			continue
+		}
+		if ch, ok := namedType.Underlying().(*types.Chan); ok {
+			// WebSocket's messages
+			if socket == nil {
+				socket = &socketDesc{}
+			}
+			t, err := b.rootStructDesc(ch.Elem())
+			if err != nil {
+				return nil, errors.Wrapf(err, "converting message type '%v' to type description", ch.Elem())
+			}
+			switch {
+			case ch.Dir() == types.RecvOnly && socket.client == nil:
+				socket.client = t
+			case ch.Dir() == types.SendOnly && socket.server == nil:
+				socket.server = t
+			default:
+				return nil, errors.Errorf("unexpected channel parameter '%v'", namedType)
+			}
+			continue
+		}
 
 		if inType != nil {
 			return nil, errors.New("handler has more than one request type")
@@ -67,6 +98,14 @@ func (b builder) getHandleDesc(fnIdent *ast.Ident, ms *types.MethodSet) (*hdlTyp
 			if outType != nil {
 				return nil, errors.New("handler has more than one response type")
 			}
+			if ch, ok := t.Underlying().(*types.Chan); ok {
+				// streamed element by element
+				if ch.Dir() == types.SendOnly {
+					return nil, errors.Errorf("result channel '%v' should be receivable", t)
+				}
+				stream = true
+				t = ch.Elem()
+			}
 
 			var err error
 			outType, err = b.rootStructDesc(t)
@@ -77,9 +116,15 @@ func (b builder) getHandleDesc(fnIdent *ast.Ident, ms *types.MethodSet) (*hdlTyp
 	}
 
 	ret := &hdlTypesDesc{
+		sig:               sig,
 		hasResponseWriter: hasResponseWriter,
 		inType:            inType,
 		outType:           outType,
+		stream:            stream,
+		socket:            socket,
+	}
+	if socket != nil && (socket.client == nil || socket.server == nil || outType != nil) {
+		return nil, errors.New("WebSocket handler should accept both message channels and return error only")
 	}
 	if f != nil {
 		pathdesc, _ := astutil.PathEnclosingInterval(f, sel.Obj().Pos(), sel.Obj().Pos())
@@ -92,7 +137,24 @@ func (b builder) getHandleDesc(fnIdent *ast.Ident, ms *types.MethodSet) (*hdlTyp
 				fd.Name.Name != fnIdent.Name {
 				continue
 			}
-			ret.description = fd.Doc.Text()
+			doc, dirs := parseDoc(fd.Doc)
+			resps, err := b.getResponses(dirs)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing directives of '%v'", fnIdent.Name)
+			}
+			ret.description = doc
+			ret.responses = resps
+		}
+	}
+
+	// document inferred error codes unless they're declared explicitly
+	declared := map[int]bool{}
+	for _, r := range ret.responses {
+		declared[r.code] = true
+	}
+	for _, code := range b.inferErrorCodes(sel.Obj().(*types.Func)) {
+		if !declared[code] {
+			ret.responses = append(ret.responses, respDesc{code: code})
 		}
 	}
 	return ret, nil
@@ -14,9 +14,16 @@ import (
 const descPkgName = "github.com/utrack/pontoon/sdesc"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "mock" {
+		mockMain(os.Args[2:])
+		return
+	}
+
 	dir := flag.String("dir", ".", "directory to parse files from")
 	help := flag.Bool("help", false, "print help string and exit")
 	recursive := flag.Bool("recursive", false, "generate defs for all child modules recursively")
+	client := flag.Bool("client", false, "generate typed Go clients of the services")
+	ts := flag.Bool("ts", false, "generate TypeScript types and fetch clients of the services")
 
 	flag.Parse()
 	if *help {
@@ -24,16 +31,108 @@ func main() {
 		return
 	}
 
+	for _, ps := range loadServices(*dir, *recursive) {
+		pkg, svcs := ps.pkg, ps.svcs
+		for _, svc := range svcs {
+			buf, err := genOpenAPI(svcs, pkg.String())
+			if err != nil {
+				log.Fatal("when generating OpenAPI 3: ", err)
+			}
+
+			if !filepath.IsAbs(svc.filename) {
+				panic(svc.filename + "<- path is not absolute")
+			}
+
+			dir := filepath.Dir(svc.filename)
+			path := filepath.Join(dir, strcase.ToSnake(svc.serviceStructName)+".pontoon.go")
+
+			bindings, imports, err := genBindings(svc, pkg.Types)
+			if err != nil {
+				log.Printf("skipping RegisterHTTPHandlers of %v: %v", svc.name, err)
+				bindings, imports = "", nil
+			}
+
+			res, err := tplGen(tplRequest{
+				Content:           string(buf),
+				PkgPath:           pkg.PkgPath,
+				PkgName:           pkg.Name,
+				HandlerStructName: svc.serviceStructName,
+				Imports:           imports,
+				Bindings:          bindings,
+			})
+			if err != nil {
+				log.Fatal("when executing go code template: ", err)
+			}
+			fout, err := os.Create(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			defer fout.Close()
+			_, err = fout.Write(res)
+			if err != nil {
+				log.Fatal("when writing a file: ", err)
+			}
+			fout.Close()
+
+			if *client {
+				writeClient(svc, pkg, filepath.Join(dir, strcase.ToSnake(svc.serviceStructName)+"_client.pontoon.go"))
+			}
+			if *ts {
+				res, err := genTypeScript(svc, pkg.PkgPath, pkg.Name)
+				if err != nil {
+					log.Fatal("when generating TypeScript of "+svc.name+": ", err)
+				}
+				err = os.WriteFile(filepath.Join(dir, strcase.ToSnake(svc.serviceStructName)+".pontoon.ts"), res, 0o644)
+				if err != nil {
+					log.Fatal("when writing a file: ", err)
+				}
+			}
+		}
+	}
+
+}
+
+func writeClient(svc serviceDesc, pkg *packages.Package, path string) {
+	c, imports, err := genClient(svc, pkg.Types)
+	if err != nil {
+		log.Fatal("when generating a client of "+svc.name+": ", err)
+	}
+	res, err := tplGenClient(tplClientRequest{
+		PkgPath: pkg.PkgPath,
+		PkgName: pkg.Name,
+		Imports: imports,
+		Client:  c,
+	})
+	if err != nil {
+		log.Fatal("when executing go code template: ", err)
+	}
+	err = os.WriteFile(path, res, 0o644)
+	if err != nil {
+		log.Fatal("when writing a file: ", err)
+	}
+}
+
+// pkgServices are the services of a package.
+type pkgServices struct {
+	pkg  *packages.Package
+	svcs []serviceDesc
+}
+
+// loadServices parses the packages in dir and describes their services.
+func loadServices(dir string, recursive bool) []pkgServices {
 	pcfg := packages.Config{
 		Mode: packages.NeedImports |
 			packages.NeedName |
 			packages.NeedDeps |
 			packages.NeedTypes |
-			packages.NeedSyntax,
-		Dir: *dir,
+			packages.NeedTypesInfo |
+			packages.NeedSyntax |
+			packages.NeedModule,
+		Dir: dir,
 	}
 	parsePath := "."
-	if *recursive {
+	if recursive {
 		parsePath = "./..."
 	}
 	srcPkgs, err := packages.Load(&pcfg, parsePath, descPkgName)
@@ -69,11 +168,17 @@ func main() {
 	}
 	if descIface == nil {
 		log.Println("project does not use pontoon - exiting")
-		return
+		return nil
 	}
 
+	allPkgs := map[string]*packages.Package{}
+	packages.Visit(srcPkgs, nil, func(p *packages.Package) {
+		allPkgs[p.PkgPath] = p
+	})
+
+	ret := []pkgServices{}
 	for _, pkg := range pkgs {
-		bu := builder{pkg: pkg, muxType: descMux}
+		bu := builder{pkg: pkg, muxType: descMux, pkgs: allPkgs}
 
 		scope := pkg.Types.Scope()
 		svcs := []serviceDesc{}
@@ -102,43 +207,9 @@ func main() {
 			}
 			svcs = append(svcs, *svc)
 		}
-
-		for _, svc := range svcs {
-			buf, err := genOpenAPI(svcs, pkg.String())
-			if err != nil {
-				log.Fatal("when generating OpenAPI 3: ", err)
-			}
-
-			if !filepath.IsAbs(svc.filename) {
-				panic(svc.filename + "<- path is not absolute")
-			}
-
-			dir := filepath.Dir(svc.filename)
-			path := filepath.Join(dir, strcase.ToSnake(svc.serviceStructName)+".pontoon.go")
-
-			res, err := tplGen(tplRequest{
-				Content:           string(buf),
-				PkgPath:           pkg.PkgPath,
-				PkgName:           pkg.Name,
-				HandlerStructName: svc.serviceStructName,
-			})
-			if err != nil {
-				log.Fatal("when executing go code template: ", err)
-			}
-			fout, err := os.Create(path)
-			if err != nil {
-				log.Fatal(err)
-			}
-
-			defer fout.Close()
-			_, err = fout.Write(res)
-			if err != nil {
-				log.Fatal("when writing a file: ", err)
-			}
-			fout.Close()
-		}
+		ret = append(ret, pkgServices{pkg: pkg, svcs: svcs})
 	}
-
+	return ret
 }
 
 func getDescType(pkg *packages.Package) (*types.Interface, *types.Interface, error) {
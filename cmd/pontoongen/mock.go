@@ -0,0 +1,437 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+)
+
+// mockTime is the value of the mocked timestamps.
+var mockTime = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// mockStreamLen is the number of elements of the mocked streams.
+const mockStreamLen = 3
+
+// mockMain serves every route of the services with synthetic responses:
+//
+//	pontoongen mock -dir ./api -addr localhost:8080
+func mockMain(args []string) {
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory to parse files from")
+	addr := fs.String("addr", "localhost:8080", "address to serve the mocks at")
+	recursive := fs.Bool("recursive", false, "mock the services of all child modules recursively")
+	_ = fs.Parse(args)
+
+	mux := http.NewServeMux()
+	n := 0
+	for _, ps := range loadServices(*dir, *recursive) {
+		for _, svc := range ps.svcs {
+			for _, h := range svc.handlers {
+				pattern := h.httpVerb + " " + h.path
+				hdl, err := mockHandler(h)
+				if err != nil {
+					log.Fatalf("when mocking '%v' of %v: %v", pattern, svc.name, err)
+				}
+				mux.Handle(pattern, hdl)
+				log.Printf("mocking %v", pattern)
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		log.Fatal("no routes to mock")
+	}
+
+	log.Printf("serving %d mocked routes at http://%v", n, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mockCORS(mux)))
+}
+
+// mockCORS allows the mocks to be called from any origin,
+// i.e. from the frontend's dev server.
+func mockCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// preflight
+		w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// mockHandler responds like the handler would, with a synthetic result.
+func mockHandler(h hdlDesc) (http.Handler, error) {
+	switch {
+	case h.inout.socket != nil:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "WebSocket routes aren't mocked", http.StatusNotImplemented)
+		}), nil
+	case h.inout.hasResponseWriter || h.inout.outType == nil:
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}), nil
+	}
+
+	v, err := mockValue(h.inout.outType, nil, "", map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding mocked result")
+	}
+
+	if h.inout.stream {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ndjson := strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+			if ndjson {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+			} else {
+				w.Header().Set("Content-Type", "text/event-stream")
+			}
+			w.Header().Set("Cache-Control", "no-cache")
+			for i := 0; i < mockStreamLen; i++ {
+				if ndjson {
+					fmt.Fprintf(w, "%s\n", body)
+					continue
+				}
+				fmt.Fprintf(w, "data: %s\n\n", body)
+			}
+		}), nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+		_, _ = w.Write([]byte("\n"))
+	}), nil
+}
+
+// mockValue returns a value of the type's JSON; sc is the schema
+// of the field, if any, constraining the value by its validate:"..." tag.
+// example is the declared example or default of the value, if any.
+// Structs already being mocked are cut short by null,
+// empty arrays and objects.
+func mockValue(t *typeDesc, sc *openapi3.Schema, example string, visiting map[string]bool) (interface{}, error) {
+	switch {
+	case t.isPtr != nil:
+		if t.isPtr.isStruct != nil && visiting[t.isPtr.id] {
+			return nil, nil
+		}
+		return mockValue(t.isPtr, sc, example, visiting)
+	case t.isScalar:
+		if example != "" {
+			return scalarValue(t, example)
+		}
+		if len(t.enum) > 0 && (sc == nil || len(sc.Enum) == 0) {
+			return t.enum[0].value, nil
+		}
+		return mockScalar(t, sc)
+	case t.isAny:
+		return nil, nil
+	case t.isSpecial == specialTypeTime:
+		if example != "" {
+			return example, nil
+		}
+		return mockTime.Format(time.RFC3339), nil
+	case t.isSpecial == specialTypeFile:
+		return "", nil
+	case t.isSlice != nil:
+		if t.isSlice.t.typeName == "byte" || t.isSlice.t.typeName == "uint8" {
+			if example == "" {
+				example = "string"
+			}
+			return base64.StdEncoding.EncodeToString([]byte(example)), nil
+		}
+		if t.isSlice.t.isStruct != nil && visiting[t.isSlice.t.id] {
+			return []interface{}{}, nil
+		}
+		n := uint64(1)
+		var items *openapi3.Schema
+		if sc != nil {
+			if sc.MinItems > n {
+				n = sc.MinItems
+			}
+			if sc.MaxItems != nil && *sc.MaxItems < n {
+				n = *sc.MaxItems
+			}
+			if sc.Items != nil {
+				items = sc.Items.Value
+			}
+		}
+		ret := []interface{}{}
+		for i := uint64(0); i < n; i++ {
+			v, err := mockValue(t.isSlice.t, items, "", visiting)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+		}
+		return ret, nil
+	case t.isMap != nil:
+		if t.isMap.value.isStruct != nil && visiting[t.isMap.value.id] {
+			return map[string]interface{}{}, nil
+		}
+		v, err := mockValue(t.isMap.value, nil, "", visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": v}, nil
+	case t.isStruct != nil:
+		visiting[t.id] = true
+		defer delete(visiting, t.id)
+
+		ret := map[string]interface{}{}
+		err := mockStruct(t, ret, visiting)
+		return ret, err
+	}
+	return nil, errors.Errorf("cannot mock type '%v'", t.typeName)
+}
+
+// mockStruct sets the JSON fields of the struct, flattening its embeds.
+func mockStruct(t *typeDesc, ret map[string]interface{}, visiting map[string]bool) error {
+	for _, e := range t.isStruct.embeds {
+		et := e.t
+		if et.isPtr != nil {
+			et = et.isPtr
+		}
+		name := genJSONFieldName("", e.tags)
+		switch {
+		case name == "-":
+		case name != "":
+			// tagged embeds aren't flattened
+			v, err := mockValue(e.t, nil, "", visiting)
+			if err != nil {
+				return errors.Wrapf(err, "embedded field '%v'", name)
+			}
+			ret[name] = v
+		case et.isStruct != nil:
+			if err := mockStruct(et, ret, visiting); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range t.isStruct.fields {
+		if !tsInJSON(f) {
+			continue
+		}
+		example := exampleTag(f.tags)
+		if props := genInProps(f.tags); example == "" && props != nil {
+			example = props.defValue
+		}
+		ref, err := genFieldSchema(f)
+		if err == nil {
+			err = genValidate(ref, f)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "field '%v'", f.name)
+		}
+		v, err := mockValue(f.t, ref.Value, example, visiting)
+		if err != nil {
+			return errors.Wrapf(err, "field '%v'", f.name)
+		}
+		ft := f.t
+		if ft.isPtr != nil {
+			ft = ft.isPtr
+		}
+		if ft.isScalar && v != nil && hasString(tsJSONOptions(f.tags), "string") {
+			// quoted scalars
+			b, _ := json.Marshal(v)
+			v = string(b)
+		}
+		ret[genJSONFieldName(f.name, f.tags)] = v
+	}
+	return nil
+}
+
+// mockFormats are the values of the string formats.
+var mockFormats = map[string]string{
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"uuid":      "123e4567-e89b-42d3-a456-426614174000",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"hostname":  "example.com",
+	"date-time": "2024-01-01T12:00:00Z",
+	"byte":      "c3RyaW5n",
+}
+
+// mockPatterns are the values matching validatePatterns.
+var mockPatterns = map[string]string{
+	validatePatterns["alpha"]:       "string",
+	validatePatterns["alphanum"]:    "string1",
+	validatePatterns["numeric"]:     "1",
+	validatePatterns["number"]:      "1",
+	validatePatterns["hexadecimal"]: "0f",
+	validatePatterns["lowercase"]:   "string",
+	validatePatterns["uppercase"]:   "STRING",
+}
+
+// mockScalar returns a scalar satisfying the schema's format, pattern,
+// length and bounds. Values of schemas it can't satisfy
+// should be set by the field's example:"..." tag.
+func mockScalar(t *typeDesc, sc *openapi3.Schema) (interface{}, error) {
+	var v interface{}
+	switch {
+	case sc != nil && len(sc.Enum) > 0:
+		v = sc.Enum[0]
+	case tsScalar(t) == "string":
+		v = mockString(sc)
+	case tsScalar(t) == "boolean":
+		v = true
+	default:
+		v = mockNumber(t, sc)
+	}
+	if sc == nil {
+		return v, nil
+	}
+
+	// the schema checks the JSON's values
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding mocked value")
+	}
+	var jv interface{}
+	if err := json.Unmarshal(b, &jv); err != nil {
+		return nil, errors.Wrap(err, "decoding mocked value")
+	}
+	if err := sc.VisitJSON(jv); err != nil {
+		return nil, errors.Wrapf(err, "mocked value %s doesn't satisfy the schema, set its example", b)
+	}
+	return v, nil
+}
+
+// mockString returns a string of the schema's format; literal patterns,
+// i.e. of startswith or contains rules, are added around it.
+func mockString(sc *openapi3.Schema) string {
+	if sc == nil {
+		return "string"
+	}
+	body, ok := mockFormats[sc.Format]
+	if !ok {
+		body = "string"
+	}
+	var prefix, infix, suffix string
+	patterns := []string{sc.Pattern}
+	for _, ref := range sc.AllOf {
+		if ref.Value != nil {
+			patterns = append(patterns, ref.Value.Pattern)
+		}
+	}
+	for _, p := range patterns {
+		if v, ok := mockPatterns[p]; ok {
+			body = v
+			continue
+		}
+		lit, begin, end := literalPattern(p)
+		switch {
+		case lit == "":
+		case begin:
+			prefix += lit
+		case end:
+			suffix = lit + suffix
+		default:
+			infix += lit
+		}
+	}
+
+	rs := []rune(body)
+	fixed := utf8.RuneCountInString(prefix + infix + suffix)
+	for uint64(len(rs)+fixed) < sc.MinLength {
+		rs = append(rs, rs[len(rs)-1])
+	}
+	if sc.MaxLength != nil && uint64(len(rs)+fixed) > *sc.MaxLength {
+		n := int(*sc.MaxLength) - fixed
+		if n < 0 {
+			n = 0
+		}
+		rs = rs[:n]
+	}
+	return prefix + string(rs) + infix + suffix
+}
+
+// literalPattern returns the literal of patterns like "^foo", "foo$"
+// or "foo"; begin and end are true if it's anchored.
+func literalPattern(p string) (lit string, begin, end bool) {
+	re, err := syntax.Parse(p, syntax.Perl)
+	if err != nil {
+		return "", false, false
+	}
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+	if len(subs) > 0 && subs[0].Op == syntax.OpBeginText {
+		begin, subs = true, subs[1:]
+	}
+	if len(subs) > 0 && subs[len(subs)-1].Op == syntax.OpEndText {
+		end, subs = true, subs[:len(subs)-1]
+	}
+	if len(subs) != 1 || subs[0].Op != syntax.OpLiteral || subs[0].Flags&syntax.FoldCase != 0 {
+		return "", false, false
+	}
+	return string(subs[0].Rune), begin, end
+}
+
+// mockNumber returns a number within the schema's bounds.
+func mockNumber(t *typeDesc, sc *openapi3.Schema) interface{} {
+	float := strings.HasPrefix(t.typeName, "float")
+	v := 1.0
+	if float {
+		v = 1.5
+	}
+	if sc != nil && sc.Min != nil && (v < *sc.Min || (sc.ExclusiveMin && v == *sc.Min)) {
+		v = *sc.Min
+		if sc.ExclusiveMin {
+			v = nextNumber(v, float, 1)
+		}
+	}
+	if sc != nil && sc.Max != nil && (v > *sc.Max || (sc.ExclusiveMax && v == *sc.Max)) {
+		v = *sc.Max
+		switch {
+		case sc.ExclusiveMax && float && sc.Min != nil:
+			v = (*sc.Min + *sc.Max) / 2
+		case sc.ExclusiveMax:
+			v = nextNumber(v, float, -1)
+		}
+	}
+	if float {
+		return v
+	}
+	if v != math.Trunc(v) {
+		v = math.Ceil(v)
+	}
+	return int64(v)
+}
+
+// nextNumber returns the closest number after the bound in the direction.
+func nextNumber(bound float64, float bool, dir float64) float64 {
+	if float {
+		return bound + dir*0.5
+	}
+	if dir > 0 {
+		return math.Floor(bound) + 1
+	}
+	return math.Ceil(bound) - 1
+}
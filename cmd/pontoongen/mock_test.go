@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// pathParams match the parameters of path patterns.
+var pathParams = regexp.MustCompile(`\{[^}]+\}`)
+
+// TestMockResponsesMatchSpec checks the mocked responses
+// of the fixtures against their specs.
+func TestMockResponsesMatchSpec(t *testing.T) {
+	for _, dir := range []string{"../../test", "../../test3"} {
+		svc, _ := loadTestService(t, dir)
+		raw, err := genOpenAPI([]serviceDesc{svc}, svc.pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := openapi3.NewLoader().LoadFromData(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, h := range svc.handlers {
+			if h.inout.socket != nil {
+				continue
+			}
+			name := h.httpVerb + " " + h.path
+			t.Run(name, func(t *testing.T) {
+				hdl, err := mockHandler(h)
+				if err != nil {
+					t.Fatal(err)
+				}
+				item := doc.Paths.Find(h.path)
+				route := &routers.Route{
+					Spec:      doc,
+					Path:      h.path,
+					PathItem:  item,
+					Method:    h.httpVerb,
+					Operation: item.GetOperation(h.httpVerb),
+				}
+
+				r := httptest.NewRequest(h.httpVerb, pathParams.ReplaceAllString(h.path, "1"), nil)
+				if h.inout.stream {
+					r.Header.Set("Accept", "application/x-ndjson")
+				}
+				w := httptest.NewRecorder()
+				hdl.ServeHTTP(w, r)
+
+				switch {
+				case h.inout.stream:
+					checkMockStream(t, route, w)
+					return
+				case w.Body.Len() == 0:
+					// like the handlers returning an error only
+					if route.Operation.Responses.Get(w.Code) == nil {
+						t.Errorf("status %v isn't described", w.Code)
+					}
+					return
+				}
+				err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+					RequestValidationInput: &openapi3filter.RequestValidationInput{
+						Request: r,
+						Route:   route,
+					},
+					Status: w.Code,
+					Header: w.Header(),
+					Body:   io.NopCloser(w.Body),
+					Options: &openapi3filter.Options{
+						IncludeResponseStatus: true,
+						MultiError:            true,
+					},
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+// checkMockStream checks every NDJSON line against the schema of the stream.
+func checkMockStream(t *testing.T, route *routers.Route, w *httptest.ResponseRecorder) {
+	t.Helper()
+	rsp := route.Operation.Responses.Get(w.Code)
+	if rsp == nil {
+		t.Fatalf("status %v isn't described", w.Code)
+	}
+	mt := rsp.Value.Content.Get(w.Header().Get("Content-Type"))
+	if mt == nil {
+		t.Fatalf("media type '%v' isn't described", w.Header().Get("Content-Type"))
+	}
+	sc := bufio.NewScanner(strings.NewReader(w.Body.String()))
+	n := 0
+	for sc.Scan() {
+		var v interface{}
+		if err := json.Unmarshal(sc.Bytes(), &v); err != nil {
+			t.Fatal(err)
+		}
+		if err := mt.Schema.Value.VisitJSON(v); err != nil {
+			t.Errorf("line %v: %v", n, err)
+		}
+		n++
+	}
+	if n != mockStreamLen {
+		t.Errorf("got %v elements, want %v", n, mockStreamLen)
+	}
+}
+
+func TestMockScalar(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	length := func(v uint64) *uint64 { return &v }
+	str := &typeDesc{isScalar: true, typeName: "string"}
+	integer := &typeDesc{isScalar: true, typeName: "int"}
+	float := &typeDesc{isScalar: true, typeName: "float64"}
+
+	tests := []struct {
+		name string
+		t    *typeDesc
+		sc   *openapi3.Schema
+		want interface{}
+	}{
+		{"string", str, nil, "string"},
+		{"email", str, &openapi3.Schema{Type: "string", Format: "email"}, "user@example.com"},
+		{"min length", str, &openapi3.Schema{Type: "string", MinLength: 8}, "stringgg"},
+		{"max length", str, &openapi3.Schema{Type: "string", MaxLength: length(3)}, "str"},
+		{"starts with", str, &openapi3.Schema{Type: "string", Pattern: "^P-", MinLength: 9}, "P-stringg"},
+		{"ends and contains", str, &openapi3.Schema{Type: "string", Pattern: `\.go$`, AllOf: openapi3.SchemaRefs{
+			openapi3.NewSchemaRef("", &openapi3.Schema{Pattern: "_"}),
+		}}, "string_.go"},
+		{"uppercase", str, &openapi3.Schema{Type: "string", Pattern: validatePatterns["uppercase"]}, "STRING"},
+		{"enum", str, &openapi3.Schema{Type: "string", Enum: []interface{}{"new", "sale"}}, "new"},
+		{"integer", integer, &openapi3.Schema{Type: "integer"}, int64(1)},
+		{"minimum", integer, &openapi3.Schema{Type: "integer", Min: ptr(10)}, int64(10)},
+		{"exclusive minimum", integer, &openapi3.Schema{Type: "integer", Min: ptr(10), ExclusiveMin: true}, int64(11)},
+		{"exclusive maximum", integer, &openapi3.Schema{Type: "integer", Max: ptr(0), ExclusiveMax: true}, int64(-1)},
+		{"float within", float, &openapi3.Schema{Type: "number", Min: ptr(0), Max: ptr(1), ExclusiveMin: true, ExclusiveMax: true}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mockScalar(tt.t, tt.sc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	_, err := mockScalar(str, &openapi3.Schema{Type: "string", Pattern: "^[0-9]{3}-[a-z]+$"})
+	if err == nil {
+		t.Error("got no error for a pattern that can't be mocked")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+// names of the components describing sdesc's error responses
+const (
+	schemaErrorResponse   = "sdesc.ErrorResponse"
+	schemaValidationError = "sdesc.ValidationError"
+	schemaFieldError      = "sdesc.FieldError"
+	schemaProblemDetails  = "sdesc.ProblemDetails"
+)
+
+const contentTypeProblem = "application/problem+json"
+
+func schemaRef(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+// errorSchemas generates error responses and tracks
+// which of sdesc's error schemas should be added to the components.
+type errorSchemas struct {
+	errorResponse   bool
+	validationError bool
+	problemDetails  bool
+}
+
+// validationResponse describes the response sdesc gives
+// to requests that don't fit the handler's input.
+func (es *errorSchemas) validationResponse(problem bool) *openapi3.Response {
+	rsp := openapi3.NewResponse().
+		WithDescription("invalid request")
+	if problem {
+		es.problemDetails = true
+		rsp.Content = newContent(contentTypeProblem, schemaRef(schemaProblemDetails))
+		return rsp
+	}
+	es.validationError = true
+	rsp.Content = openapi3.NewContentWithJSONSchemaRef(schemaRef(schemaValidationError))
+	return rsp
+}
+
+// response describes a non-200 response declared by
+// a 'response' directive or inferred from the handler's code.
+func (es *errorSchemas) response(r respDesc, problem bool) (*openapi3.Response, error) {
+	var ref *openapi3.SchemaRef
+	switch {
+	case r.t != nil:
+		var err error
+		ref, err = genRefOut(r.t)
+		if err != nil {
+			return nil, err
+		}
+	case problem:
+		es.problemDetails = true
+		ref = schemaRef(schemaProblemDetails)
+	default:
+		es.errorResponse = true
+		ref = schemaRef(schemaErrorResponse)
+	}
+
+	desc := http.StatusText(r.code)
+	if desc == "" {
+		desc = "error"
+	}
+	rsp := openapi3.NewResponse().WithDescription(desc)
+	if problem {
+		rsp.Content = newContent(contentTypeProblem, ref)
+	} else {
+		rsp.Content = openapi3.NewContentWithJSONSchemaRef(ref)
+	}
+	return rsp, nil
+}
+
+// addTo adds the referenced schemas to the components.
+func (es errorSchemas) addTo(schemas openapi3.Schemas) {
+	if es.errorResponse {
+		er := openapi3.NewObjectSchema().
+			WithProperty("error", stringSchema("Error message"))
+		er.Required = []string{"error"}
+		er.Description = "Default body of a failed call"
+
+		schemas[schemaErrorResponse] = openapi3.NewSchemaRef("", er)
+	}
+
+	if es.validationError || es.problemDetails {
+		in := stringSchema("Location of the value").
+			WithEnum(spec.LocQuery, spec.LocHeader, spec.LocPath, spec.LocForm, spec.LocBody)
+
+		fe := openapi3.NewObjectSchema().
+			WithProperty("field", stringSchema("Go name of the input struct's field")).
+			WithProperty("in", in).
+			WithProperty("name", stringSchema("Parameter name or JSON field name")).
+			WithProperty("reason", stringSchema("What's wrong with the value"))
+		fe.Required = []string{"field", "in", "name", "reason"}
+		fe.Description = "Single invalid value of the request"
+
+		schemas[schemaFieldError] = openapi3.NewSchemaRef("", fe)
+	}
+
+	if es.validationError {
+		ve := openapi3.NewObjectSchema().
+			WithProperty("error", openapi3.NewStringSchema()).
+			WithPropertyRef("fields", fieldErrorsSchema())
+		ve.Required = []string{"error"}
+		ve.Description = "Request does not fit the handler's input"
+
+		schemas[schemaValidationError] = openapi3.NewSchemaRef("", ve)
+	}
+
+	if es.problemDetails {
+		typ := stringSchema("URI reference that identifies the problem type")
+		typ.Format = "uri-reference"
+		inst := stringSchema("URI reference of this occurrence of the problem")
+		inst.Format = "uri-reference"
+		status := openapi3.NewIntegerSchema()
+		status.Description = "HTTP status code"
+
+		pd := openapi3.NewObjectSchema().
+			WithProperty("type", typ).
+			WithProperty("title", stringSchema("Short summary of the problem type")).
+			WithProperty("status", status).
+			WithProperty("detail", stringSchema("Explanation of this occurrence of the problem")).
+			WithProperty("instance", inst).
+			WithPropertyRef("invalid-params", fieldErrorsSchema())
+		pd.Required = []string{"type", "title", "status"}
+		pd.Description = "RFC 7807 problem details"
+
+		schemas[schemaProblemDetails] = openapi3.NewSchemaRef("", pd)
+	}
+}
+
+func fieldErrorsSchema() *openapi3.SchemaRef {
+	ret := openapi3.NewArraySchema()
+	ret.Items = schemaRef(schemaFieldError)
+	return openapi3.NewSchemaRef("", ret)
+}
+
+func newContent(mediaType string, ref *openapi3.SchemaRef) openapi3.Content {
+	return openapi3.Content{
+		mediaType: openapi3.NewMediaType().WithSchemaRef(ref),
+	}
+}
+
+func stringSchema(desc string) *openapi3.Schema {
+	ret := openapi3.NewStringSchema()
+	ret.Description = desc
+	return ret
+}
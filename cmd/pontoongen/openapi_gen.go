@@ -4,21 +4,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
 )
 
 // l for location
 const (
-	lBody   = "body"
-	lQuery  = "query"
-	lHeader = "header"
-	lForm   = "form"
-	lPath   = "path"
+	lBody   = spec.LocBody
+	lQuery  = spec.LocQuery
+	lHeader = spec.LocHeader
+	lForm   = spec.LocForm
+	lPath   = spec.LocPath
+)
+
+// media types of the forms
+const (
+	mediaTypeURLEncoded = "application/x-www-form-urlencoded"
+	mediaTypeMultipart  = "multipart/form-data"
 )
 
 func genOpenAPI(ss []serviceDesc, pkgName string) ([]byte, error) {
@@ -27,6 +37,19 @@ func genOpenAPI(ss []serviceDesc, pkgName string) ([]byte, error) {
 
 	tags := []*openapi3.Tag{}
 
+	errSchemas := errorSchemas{}
+
+	schemes := openapi3.SecuritySchemes{}
+	for _, s := range ss {
+		for name, sd := range s.securitySchemes {
+			sch := genSecurityScheme(sd)
+			if cur, ok := schemes[name]; ok && !reflect.DeepEqual(cur.Value, sch) {
+				return nil, errors.Errorf("security scheme '%v' is declared differently by several services", name)
+			}
+			schemes[name] = &openapi3.SecuritySchemeRef{Value: sch}
+		}
+	}
+
 	for _, s := range ss {
 		tags = append(tags, &openapi3.Tag{
 			Name:        s.name,
@@ -45,7 +68,7 @@ func genOpenAPI(ss []serviceDesc, pkgName string) ([]byte, error) {
 			if err != nil {
 				return nil, errors.Wrap(err, "annotating handler")
 			}
-			op.Tags = []string{s.name}
+			op.Tags = append([]string{s.name}, h.route.tags...)
 
 			if h.inout.inType != nil {
 				// httpMethod := strings.ToUpper(h.httpVerb)
@@ -61,7 +84,7 @@ func genOpenAPI(ss []serviceDesc, pkgName string) ([]byte, error) {
 				// 	}
 				// }
 
-				err = genInSchema(h.inout.inType, op)
+				err = genInSchema(h.inout.inType, op, s.contentTypes())
 				if err != nil {
 					return nil, errors.Wrapf(err, "generating input schema for '%v'", h.path)
 				}
@@ -74,8 +97,62 @@ func genOpenAPI(ss []serviceDesc, pkgName string) ([]byte, error) {
 
 			rsp := openapi3.NewResponse()
 			rsp = rsp.WithDescription("success")
-			rsp.Content = openapi3.NewContentWithJSONSchemaRef(out)
-			op.AddResponse(200, rsp)
+			rsp.Content = openapi3.NewContentWithSchemaRef(out, s.contentTypes())
+			if h.inout.stream {
+				// the schema describes a single element
+				rsp = rsp.WithDescription("stream of the elements, one per event or line")
+				rsp.Content = openapi3.NewContentWithSchemaRef(out, streamMediaTypes)
+			}
+			if h.inout.socket != nil {
+				err = genSocket(h.inout.socket, op)
+				if err != nil {
+					return nil, errors.Wrapf(err, "generating WebSocket messages for '%v'", h.path)
+				}
+			} else {
+				op.AddResponse(200, rsp)
+			}
+
+			if h.inout.inType != nil {
+				op.AddResponse(http.StatusBadRequest, errSchemas.validationResponse(s.problemDetails))
+			}
+
+			// handler's responses override service-wide ones
+			resps := append(append([]respDesc{}, s.responses...), h.inout.responses...)
+
+			if h.route.noSecurity && len(s.security) > 0 {
+				// public route of a secured service
+				op.Security = openapi3.NewSecurityRequirements()
+			}
+			if sec := opSecurity(s, h); len(sec) > 0 {
+				for _, r := range sec {
+					if _, ok := schemes[r.scheme]; !ok {
+						return nil, errors.Errorf("'%v %v' requires undeclared security scheme '%v'", h.httpVerb, h.path, r.scheme)
+					}
+				}
+				op.Security = genSecurityRequirements(sec)
+				resps = append([]respDesc{{code: http.StatusUnauthorized}, {code: http.StatusForbidden}}, resps...)
+			}
+			// bodies are negotiated
+			negotiated := len(s.mediaTypes) > 0
+			if negotiated && op.RequestBody != nil && op.RequestBody.Value.Content.Get(s.mediaTypes[0]) != nil {
+				resps = append([]respDesc{{code: http.StatusUnsupportedMediaType}}, resps...)
+			}
+			if (negotiated || h.inout.stream) && h.inout.outType != nil && !h.inout.hasResponseWriter {
+				resps = append([]respDesc{{code: http.StatusNotAcceptable}}, resps...)
+			}
+			if h.inout.socket != nil {
+				resps = append([]respDesc{{code: http.StatusUpgradeRequired}}, resps...)
+			}
+			if h.route.hasTimeout {
+				resps = append([]respDesc{{code: http.StatusGatewayTimeout}}, resps...)
+			}
+			for _, r := range resps {
+				rsp, err := errSchemas.response(r, s.problemDetails)
+				if err != nil {
+					return nil, errors.Wrapf(err, "generating response %v for '%v'", r.code, h.path)
+				}
+				op.AddResponse(r.code, rsp)
+			}
 			p.SetOperation(h.httpVerb, op)
 		}
 	}
@@ -89,6 +166,10 @@ func genOpenAPI(ss []serviceDesc, pkgName string) ([]byte, error) {
 
 		comp.Schemas[d.typeName] = openapi3.NewSchemaRef("", t.Value)
 	}
+	errSchemas.addTo(comp.Schemas)
+	if len(schemes) > 0 {
+		comp.SecuritySchemes = schemes
+	}
 
 	root := openapi3.T{}
 	root.Info = &openapi3.Info{
@@ -112,7 +193,12 @@ func genOpenAPI(ss []serviceDesc, pkgName string) ([]byte, error) {
 
 var cacheSchemaRefs = map[*typeDesc]*openapi3.SchemaRef{}
 
-func genInSchema(t *typeDesc, sc *openapi3.Operation) error {
+// streamMediaTypes are the media types of channel handlers' results.
+var streamMediaTypes = []string{"text/event-stream", "application/x-ndjson"}
+
+// genInSchema describes the handler's input;
+// its body is encoded in one of the media types.
+func genInSchema(t *typeDesc, sc *openapi3.Operation, mediaTypes []string) error {
 	// Dereference pointers in input parameters to get the actual type
 	if t.isStruct == nil && t.isPtr != nil {
 		t = t.isPtr
@@ -148,7 +234,7 @@ func genInSchema(t *typeDesc, sc *openapi3.Operation) error {
 		if sc.RequestBody != nil && sc.RequestBody.Value != nil {
 			return errors.Errorf("multiple JSON bodies declared in a handler struct, current '%v'", fs.Ref)
 		}
-		body := openapi3.NewRequestBody().WithJSONSchemaRef(fs).WithDescription(t.doc)
+		body := openapi3.NewRequestBody().WithSchemaRef(fs, mediaTypes).WithDescription(t.doc)
 		sc.RequestBody = &openapi3.RequestBodyRef{
 			Value: body,
 		}
@@ -156,7 +242,7 @@ func genInSchema(t *typeDesc, sc *openapi3.Operation) error {
 	}
 
 	for _, f := range t.isStruct.embeds {
-		err := genInSchema(f.t, sc)
+		err := genInSchema(f.t, sc, mediaTypes)
 		if err != nil {
 			return err
 		}
@@ -172,14 +258,31 @@ func genInSchema(t *typeDesc, sc *openapi3.Operation) error {
 		if err != nil {
 			return err
 		}
+		if err := genValidate(fs, f); err != nil {
+			return err
+		}
 		if props.defValue != "" {
-			fs.Value = fs.Value.WithDefault(props.defValue)
+			v, err := typedDefault(f.t, props.defValue)
+			if err != nil {
+				return errors.Wrapf(err, "default of field '%v'", f.name)
+			}
+			fs.Value = fs.Value.WithDefault(v)
+		}
+		if props.defValue == "" && validateRequired(f.tags) {
+			props.required = true
+		}
+		if ex := exampleTag(f.tags); ex != "" {
+			v, err := scalarValue(f.t, ex)
+			if err != nil {
+				return errors.Wrapf(err, "example of field '%v'", f.name)
+			}
+			fs.Value.Example = v
 		}
 
 		doc := docFromComment(f.name, props.name, f.doc)
 		switch props.location {
 		case "body":
-			body := openapi3.NewRequestBody().WithJSONSchemaRef(fs).WithDescription(doc)
+			body := openapi3.NewRequestBody().WithSchemaRef(fs, mediaTypes).WithDescription(doc)
 			if sc.RequestBody != nil && sc.RequestBody.Value != nil {
 				return errors.Errorf("multiple JSON bodies declared in a handler struct")
 			}
@@ -209,41 +312,10 @@ func genInSchema(t *typeDesc, sc *openapi3.Operation) error {
 			if f.t.isPtr != nil {
 				t = f.t.isPtr
 			}
-			if t.isSpecial != specialTypeFile {
-				return errors.Errorf("don't know how to render non-multipart forms yet, field '%v', type '%v'", f.name, f.t.typeName)
+			if err := addFormField(sc, props.name, fs, props.required, t.isSpecial == specialTypeFile); err != nil {
+				return errors.Wrapf(err, "field '%v'", f.name)
 			}
 
-			// TODO this generates ONLY multipart/form-data!
-			if sc.RequestBody == nil {
-				sc.RequestBody = &openapi3.RequestBodyRef{}
-			}
-
-			var curMediaSchema *openapi3.SchemaRef
-
-			if sc.RequestBody.Value != nil &&
-				sc.RequestBody.Value.Content != nil {
-				curMedia := sc.RequestBody.Value.Content.Get("multipart/form-data")
-				if curMedia != nil {
-					curMediaSchema = curMedia.Schema
-				}
-			}
-
-			if curMediaSchema == nil {
-				curMediaSchema = openapi3.NewObjectSchema().NewRef()
-				curMediaSchema.Value = &openapi3.Schema{}
-			}
-
-			if curMediaSchema.Value.Properties == nil {
-				curMediaSchema.Value.Properties = openapi3.Schemas{}
-			}
-			curMediaSchema.Value.Type = openapi3.TypeObject
-
-			curMediaSchema.Value.Properties[props.name] = fs
-
-			sc.RequestBody.Value = openapi3.
-				NewRequestBody().
-				WithFormDataSchemaRef(curMediaSchema)
-
 		default:
 			return errors.Errorf("unknown in source type '%v' for field '%v'", props.location, f.name)
 		}
@@ -251,6 +323,44 @@ func genInSchema(t *typeDesc, sc *openapi3.Operation) error {
 	return nil
 }
 
+// addFormField adds the field to the form body of the operation;
+// the form is multipart/form-data once it has a file,
+// application/x-www-form-urlencoded otherwise.
+func addFormField(sc *openapi3.Operation, name string, fs *openapi3.SchemaRef, required, file bool) error {
+	form := openapi3.NewObjectSchema().NewRef()
+	multipart := file
+	if sc.RequestBody != nil && sc.RequestBody.Value != nil {
+		content := sc.RequestBody.Value.Content
+		switch {
+		case content.Get(mediaTypeMultipart) != nil:
+			form = content.Get(mediaTypeMultipart).Schema
+			multipart = true
+		case content.Get(mediaTypeURLEncoded) != nil:
+			form = content.Get(mediaTypeURLEncoded).Schema
+		default:
+			return errors.New("form fields can't be declared along with a body")
+		}
+	}
+
+	if form.Value.Properties == nil {
+		form.Value.Properties = openapi3.Schemas{}
+	}
+	form.Value.Properties[name] = fs
+	if required {
+		form.Value.Required = append(form.Value.Required, name)
+	}
+
+	mediaType := mediaTypeURLEncoded
+	if multipart {
+		mediaType = mediaTypeMultipart
+	}
+	sc.RequestBody = &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithSchemaRef(form, []string{mediaType}),
+	}
+	return nil
+}
+
 func genFieldSchema(f descField) (*openapi3.SchemaRef, error) {
 
 	if f.t.isScalar {
@@ -304,6 +414,30 @@ func genFieldSchema(f descField) (*openapi3.SchemaRef, error) {
 	panic(fmt.Sprintf("failed to generate field schema: %+v", f.t))
 }
 
+// genSocket describes a WebSocket handler's messages
+// in the operation's x-websocket extension.
+func genSocket(sd *socketDesc, op *openapi3.Operation) error {
+	client, err := genRefOut(sd.client)
+	if err != nil {
+		return errors.Wrap(err, "client's message")
+	}
+	server, err := genRefOut(sd.server)
+	if err != nil {
+		return errors.Wrap(err, "server's message")
+	}
+
+	op.AddResponse(http.StatusSwitchingProtocols, openapi3.NewResponse().
+		WithDescription("switched to WebSocket; every message is a JSON text frame"))
+	if op.Extensions == nil {
+		op.Extensions = map[string]interface{}{}
+	}
+	op.Extensions["x-websocket"] = map[string]interface{}{
+		"clientMessage": client,
+		"serverMessage": server,
+	}
+	return nil
+}
+
 func genRefOut(t *typeDesc) (*openapi3.SchemaRef, error) {
 	if t == nil {
 		return nil, nil
@@ -369,12 +503,26 @@ func genRefFieldStruct(t *typeDesc) (*openapi3.SchemaRef, error) {
 			case lForm, lHeader, lQuery, lPath:
 				continue
 			}
-			if props.required {
-				sc.Required = append(sc.Required, fname)
+		}
+		if err := genValidate(ref, f); err != nil {
+			return nil, err
+		}
+		switch {
+		case props != nil && props.defValue != "":
+			v, err := typedDefault(f.t, props.defValue)
+			if err != nil {
+				return nil, errors.Wrapf(err, "default of field '%v'", f.name)
 			}
-			if props.defValue != "" {
-				ref.Value = ref.Value.WithDefault(props.defValue)
+			ref.Value = ref.Value.WithDefault(v)
+		case props != nil && props.required, validateRequired(f.tags):
+			sc.Required = append(sc.Required, fname)
+		}
+		if ex := exampleTag(f.tags); ex != "" {
+			v, err := scalarValue(f.t, ex)
+			if err != nil {
+				return nil, errors.Wrapf(err, "example of field '%v'", f.name)
 			}
+			ref.Value.Example = v
 		}
 		sc.Properties[fname] = ref
 	}
@@ -391,43 +539,63 @@ type inProps struct {
 func genInProps(tags string) *inProps {
 	tags = strings.Trim(tags, "`")
 	tag := reflect.StructTag(tags)
-	tagval := tag.Get("in")
-	if len(tagval) == 0 || tagval == "-" {
+	in := spec.ParseIn(tag.Get("in"))
+	if in == nil {
 		return nil
 	}
-
-	spl := strings.Split(tagval, ";")
-
-	ret := &inProps{}
-	for _, s := range spl {
-		if s == "required" {
-			ret.required = true
-			continue
-		}
-		src2name := strings.SplitN(s, "=", 2)
-
-		directive := src2name[0]
-		value := src2name[1]
-		switch directive {
-		case lBody, lForm, lHeader, lQuery, lPath:
-			ret.location = directive
-			ret.name = strings.Split(value, ",")[0]
-		case "default":
-			ret.defValue = value
-		}
+	return &inProps{
+		name:     in.Name,
+		location: in.Location,
+		// the binders never find a value with a default missing
+		required: in.Required && in.Default == "",
+		defValue: in.Default,
 	}
-	return ret
 }
 
 func genJSONFieldName(name, tags string) string {
 	tags = strings.Trim(tags, "`")
 	tag := reflect.StructTag(tags)
-	ret := tag.Get("json")
-	ret = strings.TrimSuffix(ret, ",omitempty")
-	if ret != "" {
-		return ret
+	return spec.JSONName(name, tag.Get("json"))
+}
+
+// exampleTag returns the field's example:"..." value.
+func exampleTag(tags string) string {
+	return reflect.StructTag(strings.Trim(tags, "`")).Get("example")
+}
+
+// scalarValue converts the example or default of a scalar or time
+// to the value of its JSON.
+func scalarValue(t *typeDesc, s string) (interface{}, error) {
+	if t.isPtr != nil {
+		t = t.isPtr
 	}
-	return name
+	switch {
+	case t.isSpecial == specialTypeTime:
+		_, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad time '%v'", s)
+		}
+		return s, nil
+	case !t.isScalar:
+		return nil, errors.Errorf("only scalars and times can have examples, got '%v'", t.typeName)
+	}
+
+	switch t.typeName {
+	case "string":
+		return s, nil
+	case "bool":
+		v, err := strconv.ParseBool(s)
+		return v, errors.Wrapf(err, "bad bool '%v'", s)
+	case "float32", "float64":
+		v, err := strconv.ParseFloat(s, 64)
+		return v, errors.Wrapf(err, "bad number '%v'", s)
+	}
+	if strings.HasPrefix(t.typeName, "uint") {
+		v, err := strconv.ParseUint(s, 10, 64)
+		return v, errors.Wrapf(err, "bad unsigned integer '%v'", s)
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	return v, errors.Wrapf(err, "bad integer '%v'", s)
 }
 
 func genRefFieldAny(t *typeDesc) (*openapi3.SchemaRef, error) {
@@ -436,6 +604,8 @@ func genRefFieldAny(t *typeDesc) (*openapi3.SchemaRef, error) {
 	}
 
 	sc := openapi3.NewSchema()
+	// nil interfaces are marshaled as null
+	sc.Nullable = true
 
 	return openapi3.NewSchemaRef("", sc), nil
 }
@@ -481,9 +651,32 @@ func genRefFieldScalar(t *typeDesc) (*openapi3.SchemaRef, error) {
 	default:
 		return nil, errors.Errorf("unknown scalar field type '%v'", t.typeName)
 	}
+	genEnum(sc, t.enum)
 	return openapi3.NewSchemaRef("", sc), nil
 }
 
+// genEnum sets the schema's enum to the values of the named scalar;
+// docs of the values are listed in x-enum-descriptions.
+func genEnum(sc *openapi3.Schema, enum []descEnum) {
+	if len(enum) == 0 {
+		return
+	}
+	docs := make([]string, len(enum))
+	hasDocs := false
+	for i, e := range enum {
+		sc.Enum = append(sc.Enum, e.value)
+		docs[i] = docFromComment(e.name, "", e.doc)
+		hasDocs = hasDocs || docs[i] != ""
+	}
+	if !hasDocs {
+		return
+	}
+	if sc.Extensions == nil {
+		sc.Extensions = map[string]interface{}{}
+	}
+	sc.Extensions["x-enum-descriptions"] = docs
+}
+
 func genRefFieldSlice(t *typeDesc) (*openapi3.SchemaRef, error) {
 	if t == nil {
 		return nil, nil
@@ -598,13 +791,12 @@ func annotateHandler(h hdlDesc, op *openapi3.Operation) error {
 	op.Summary = summary
 	op.Description = desc
 
-	pathCamelCase := strings.ReplaceAll(h.path, "/", "_")
-	pathCamelCase = strings.ReplaceAll(pathCamelCase, "{", "_")
-	pathCamelCase = strings.ReplaceAll(pathCamelCase, "}", "_")
-	pathCamelCase = strings.TrimPrefix(pathCamelCase, "_")
-	op.OperationID = strings.ToLower(pathCamelCase + "_" + h.httpVerb)
+	op.OperationID = spec.OperationID(h.httpVerb, h.path)
+	if h.route.operationID != "" {
+		op.OperationID = h.route.operationID
+	}
 
-	if strings.Contains(desc, "\nDeprecated:") {
+	if strings.Contains(desc, "\nDeprecated:") || h.route.deprecated {
 		op.Deprecated = true
 	}
 	return nil
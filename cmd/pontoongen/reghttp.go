@@ -3,13 +3,16 @@ package main
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/types"
 	"reflect"
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
 )
 
 // getHandlerNames scans RegisterHTTP's function body and
@@ -32,64 +35,232 @@ func (b builder) getHandlerNames(sel *types.Func) ([]hdlPathPtr, error) {
 	funcRouterParam := funcDecl.Type.Params.List[0]
 
 	vis := &visRegHTTP{
-		muxDecl: funcRouterParam,
+		routers: map[types.Object]routerGroup{},
 		pkg:     srcPkg,
 		pkgReg:  b.pkg,
 	}
+	for _, n := range funcRouterParam.Names {
+		if obj := b.pkg.TypesInfo.Defs[n]; obj != nil {
+			vis.routers[obj] = routerGroup{}
+		}
+	}
 
 	ast.Walk(vis, funcBody)
+	if vis.err != nil {
+		return nil, vis.err
+	}
 
 	return vis.hits, nil
 }
 
+// visRegHTTP collects MethodFunc and sdesc.Handle calls of the router
+// and of its route groups, created by Route and With.
 type visRegHTTP struct {
-	muxDecl *ast.Field
+	// routers maps known HTTPRouter variables to their groups.
+	routers map[types.Object]routerGroup
 	hits    []hdlPathPtr
 	pkg     *types.Package
 	pkgReg  *packages.Package
+	// err is the first call that can't be described.
+	err error
+}
+
+// routerGroup is a route group created by Route and With.
+type routerGroup struct {
+	prefix string
+	// middlewares is true if the group was created by With.
+	middlewares bool
 }
 
 func (vr *visRegHTTP) Visit(node ast.Node) ast.Visitor {
-	if node == nil {
+	if node == nil || vr.err != nil {
 		return nil
 	}
+
+	if as, ok := node.(*ast.AssignStmt); ok {
+		// r := mux.With(mw)
+		if len(as.Lhs) != len(as.Rhs) {
+			return vr
+		}
+		for i, l := range as.Lhs {
+			id, ok := l.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			g, ok := vr.routerGroup(as.Rhs[i])
+			if !ok {
+				continue
+			}
+			if obj := vr.pkgReg.TypesInfo.ObjectOf(id); obj != nil {
+				vr.routers[obj] = g
+			}
+		}
+		return vr
+	}
+
 	cv, ok := node.(*ast.CallExpr)
 	if !ok {
 		return vr
 	}
+	if vr.visitHandle(cv) {
+		return nil
+	}
 
 	se, ok := cv.Fun.(*ast.SelectorExpr)
 	if !ok {
 		return vr
 	}
 
-	muxIdent, ok := se.X.(*ast.Ident)
+	g, ok := vr.routerGroup(se.X)
 	if !ok {
 		return vr
 	}
 
-	if muxIdent.Obj.Decl != vr.muxDecl {
+	if se.Sel.Name == "Route" {
+		// mux.Route("/v1", func(r sdesc.HTTPRouter) {...})
+		tv := vr.pkgReg.TypesInfo.Types[cv.Args[0]]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			vr.err = errors.Errorf("%v: prefix '%v' of %v should be a constant string",
+				vr.pkgReg.Fset.Position(cv.Pos()), types.ExprString(cv.Args[0]), types.ExprString(cv.Fun))
+			return nil
+		}
+		fl, ok := cv.Args[1].(*ast.FuncLit)
+		if !ok {
+			vr.err = errors.Errorf("%v: routes of %v should be registered by a func literal, got '%v'",
+				vr.pkgReg.Fset.Position(cv.Pos()), types.ExprString(cv.Fun), types.ExprString(cv.Args[1]))
+			return nil
+		}
+		if len(fl.Type.Params.List) == 0 {
+			return vr
+		}
+		group := g
+		group.prefix = spec.JoinPath(g.prefix, constant.StringVal(tv.Value))
+		for _, n := range fl.Type.Params.List[0].Names {
+			if obj := vr.pkgReg.TypesInfo.Defs[n]; obj != nil {
+				vr.routers[obj] = group
+			}
+		}
 		return vr
 	}
+	switch se.Sel.Name {
+	case "MethodFunc":
+		vr.addHit(g, cv.Args, nil)
+	case "WebSocket":
+		vr.addRoute(g, "GET", cv.Args, nil, true)
+	default:
+		return vr
+	}
+	return nil
+}
+
+// visitHandle registers sdesc.Handle[In,Out](mux, method, path, fn),
+// sdesc.HandleCtx and sdesc.HandleWebSocket calls; the handler's signature
+// is taken from the instantiated type arguments.
+func (vr *visRegHTTP) visitHandle(cv *ast.CallExpr) bool {
+	f, ok := typeutil.Callee(vr.pkgReg.TypesInfo, cv).(*types.Func)
+	if !ok || f.Pkg() == nil || f.Pkg().Path() != descPkgName {
+		return false
+	}
+	fnArg := 3
+	switch f.Name() {
+	case "Handle", "HandleCtx":
+	case "HandleWebSocket":
+		fnArg = 2
+	default:
+		return false
+	}
+	g, ok := vr.routerGroup(cv.Args[0])
+	if !ok {
+		return false
+	}
 
-	funcSelector, ok := cv.Args[2].(*ast.SelectorExpr)
+	inst, ok := vr.pkgReg.TypesInfo.TypeOf(cv.Fun).(*types.Signature)
 	if !ok {
-		return vr
+		return false
 	}
+	sig, ok := inst.Params().At(fnArg).Type().Underlying().(*types.Signature)
+	if !ok {
+		return false
+	}
+
+	if fnArg == 2 {
+		vr.addRoute(g, "GET", cv.Args[1:], sig, true)
+	} else {
+		vr.addHit(g, cv.Args[1:], sig)
+	}
+	return true
+}
+
+// addHit registers the handler given MethodFunc's arguments.
+func (vr *visRegHTTP) addHit(g routerGroup, args []ast.Expr, sig *types.Signature) {
+	argOp := vr.litFromExpr(args[0])
+	vr.addRoute(g, strings.Trim(argOp.Value, `"`), args[1:], sig, false)
+}
 
-	argOp,
-		argPath,
-		argHandlerFunc :=
-		vr.litFromExpr(cv.Args[0]),
-		vr.litFromExpr(cv.Args[1]),
-		funcSelector
+// addRoute registers the handler given the path, handler
+// and RouteOptions arguments.
+func (vr *visRegHTTP) addRoute(g routerGroup, op string, args []ast.Expr, sig *types.Signature, websocket bool) {
+	funcSelector, ok := args[1].(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	argPath := vr.litFromExpr(args[0])
+	opts, err := vr.routeOptions(args[2:])
 
 	vr.hits = append(vr.hits, hdlPathPtr{
-		op:   strings.Trim(argOp.Value, `"`),
-		path: strings.Trim(argPath.Value, `"`),
-		fn:   argHandlerFunc})
+		op:          op,
+		path:        spec.JoinPath(g.prefix, strings.Trim(argPath.Value, `"`)),
+		fn:          funcSelector,
+		sig:         sig,
+		opts:        opts,
+		optsErr:     err,
+		websocket:   websocket,
+		middlewares: g.middlewares,
+	})
+}
 
-	return nil
+// routerGroup returns the group if the expression
+// is a known router or a group created by its With method.
+func (vr *visRegHTTP) routerGroup(ex ast.Expr) (routerGroup, bool) {
+	switch v := ex.(type) {
+	case *ast.Ident:
+		obj := vr.pkgReg.TypesInfo.ObjectOf(v)
+		if obj == nil {
+			return routerGroup{}, false
+		}
+		g, ok := vr.routers[obj]
+		return g, ok
+	case *ast.ParenExpr:
+		return vr.routerGroup(v.X)
+	case *ast.CallExpr:
+		se, ok := v.Fun.(*ast.SelectorExpr)
+		if !ok || se.Sel.Name != "With" {
+			return routerGroup{}, false
+		}
+		g, ok := vr.routerGroup(se.X)
+		g.middlewares = true
+		return g, ok
+	}
+	return routerGroup{}, false
+}
+
+// routeOptions returns RouteOptions passed to MethodFunc
+// as direct calls of sdesc's funcs. It fails on the options it can't
+// resolve, i.e. variables, `opts...` or calls of other funcs.
+func (vr *visRegHTTP) routeOptions(args []ast.Expr) ([]optCall, error) {
+	ret := []optCall{}
+	for _, a := range args {
+		call, ok := a.(*ast.CallExpr)
+		if !ok {
+			return nil, errors.Errorf("option '%v' should be a call of an sdesc func", types.ExprString(a))
+		}
+		f, ok := typeutil.Callee(vr.pkgReg.TypesInfo, call).(*types.Func)
+		if !ok || f.Pkg() == nil || f.Pkg().Path() != descPkgName || !routeOptionNames[f.Name()] {
+			return nil, errors.Errorf("option '%v' should be a call of an sdesc func", types.ExprString(a))
+		}
+		ret = append(ret, optCall{name: f.Name(), call: call})
+	}
+	return ret, nil
 }
 
 func (vr *visRegHTTP) litFromExpr(ex ast.Node) *ast.BasicLit {
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"github.com/pkg/errors"
+)
+
+// routeOptionNames are the RouteOptions understood by getRouteOptions.
+var routeOptionNames = map[string]bool{
+	"WithTags":             true,
+	"WithOperationID":      true,
+	"WithDeprecated":       true,
+	"WithRouteSecurity":    true,
+	"WithoutSecurity":      true,
+	"WithTimeout":          true,
+	"WithRouteMiddlewares": true,
+	"WithOrigins":          true,
+}
+
+// getRouteOptions interprets RouteOptions passed to MethodFunc.
+func (b builder) getRouteOptions(opts []optCall) (*routeDesc, error) {
+	ret := &routeDesc{}
+	for _, o := range opts {
+		switch o.name {
+		case "WithTags":
+			tags, err := b.constStrings(o.call.Args)
+			if err != nil {
+				return nil, errors.Wrap(err, o.name)
+			}
+			ret.tags = append(ret.tags, tags...)
+		case "WithOperationID":
+			ids, err := b.constStrings(o.call.Args)
+			if err != nil {
+				return nil, errors.Wrap(err, o.name)
+			}
+			ret.operationID = ids[0]
+		case "WithDeprecated":
+			ret.deprecated = true
+		case "WithRouteSecurity":
+			args, err := b.constStrings(o.call.Args)
+			if err != nil {
+				return nil, errors.Wrap(err, o.name)
+			}
+			ret.security = append(ret.security, securityDesc{
+				scheme: args[0],
+				scopes: args[1:],
+			})
+		case "WithoutSecurity":
+			ret.noSecurity = true
+		case "WithTimeout":
+			ret.hasTimeout = true
+			tv := b.pkg.TypesInfo.Types[o.call.Args[0]]
+			if tv.Value == nil {
+				ret.dynamicTimeout = true
+				continue
+			}
+			ret.timeout, _ = constant.Int64Val(tv.Value)
+		case "WithRouteMiddlewares":
+			ret.middlewares = true
+		case "WithOrigins":
+			origins, err := b.constStrings(o.call.Args)
+			if err != nil {
+				return nil, errors.Wrap(err, o.name)
+			}
+			ret.origins = append(ret.origins, origins...)
+		}
+	}
+	return ret, nil
+}
+
+// constStrings returns values of constant string expressions.
+func (b builder) constStrings(exprs []ast.Expr) ([]string, error) {
+	ret := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		tv, ok := b.pkg.TypesInfo.Types[e]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+			return nil, errors.Errorf("argument '%v' should be a constant string", types.ExprString(e))
+		}
+		ret = append(ret, constant.StringVal(tv.Value))
+	}
+	return ret, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// schemeDesc is a security scheme declared by sdesc.WithSecurityScheme.
+type schemeDesc struct {
+	typ          string
+	scheme       string
+	bearerFormat string
+	in           string
+	name         string
+}
+
+// getSecurity interprets security options of the Service.
+func (b builder) getSecurity(opts []optCall) (map[string]schemeDesc, []securityDesc, error) {
+	schemes := map[string]schemeDesc{}
+	reqs := []securityDesc{}
+	for _, o := range opts {
+		switch o.name {
+		case "WithSecurityScheme":
+			name, err := b.constStrings(o.call.Args[:1])
+			if err != nil {
+				return nil, nil, errors.Wrap(err, o.name)
+			}
+			sd, err := b.getScheme(o.call.Args[1])
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "%v '%v'", o.name, name[0])
+			}
+			schemes[name[0]] = *sd
+		case "WithSecurity":
+			args, err := b.constStrings(o.call.Args)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, o.name)
+			}
+			reqs = append(reqs, securityDesc{scheme: args[0], scopes: args[1:]})
+		}
+	}
+	return schemes, reqs, nil
+}
+
+// getScheme interprets a call of sdesc's SecurityScheme constructor.
+func (b builder) getScheme(ex ast.Expr) (*schemeDesc, error) {
+	call, ok := ex.(*ast.CallExpr)
+	var f *types.Func
+	if ok {
+		f, _ = typeutil.Callee(b.pkg.TypesInfo, call).(*types.Func)
+	}
+	if f == nil || f.Pkg() == nil || f.Pkg().Path() != descPkgName {
+		return nil, errors.Errorf("scheme '%v' should be a direct call of sdesc's constructor", types.ExprString(ex))
+	}
+
+	args, err := b.constStrings(call.Args)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Name() {
+	case "BearerAuth":
+		return &schemeDesc{typ: "http", scheme: "bearer", bearerFormat: args[0]}, nil
+	case "BasicAuth":
+		return &schemeDesc{typ: "http", scheme: "basic"}, nil
+	case "APIKeyHeader":
+		return &schemeDesc{typ: "apiKey", in: "header", name: args[0]}, nil
+	case "APIKeyQuery":
+		return &schemeDesc{typ: "apiKey", in: "query", name: args[0]}, nil
+	case "APIKeyCookie":
+		return &schemeDesc{typ: "apiKey", in: "cookie", name: args[0]}, nil
+	}
+	return nil, errors.Errorf("unknown security scheme constructor 'sdesc.%v'", f.Name())
+}
+
+// opSecurity returns effective security requirements of the handler;
+// nil means there are none.
+func opSecurity(s serviceDesc, h hdlDesc) []securityDesc {
+	switch {
+	case h.route.noSecurity:
+		return nil
+	case len(h.route.security) > 0:
+		return h.route.security
+	}
+	return s.security
+}
+
+func genSecurityRequirements(reqs []securityDesc) *openapi3.SecurityRequirements {
+	ret := openapi3.NewSecurityRequirements()
+	for _, s := range reqs {
+		ret.With(openapi3.NewSecurityRequirement().Authenticate(s.scheme, s.scopes...))
+	}
+	return ret
+}
+
+func genSecurityScheme(sd schemeDesc) *openapi3.SecurityScheme {
+	ret := openapi3.NewSecurityScheme().WithType(sd.typ)
+	switch sd.typ {
+	case "http":
+		ret = ret.WithScheme(sd.scheme)
+		if sd.bearerFormat != "" {
+			ret = ret.WithBearerFormat(sd.bearerFormat)
+		}
+	case "apiKey":
+		ret = ret.WithIn(sd.in).WithName(sd.name)
+	}
+	return ret
+}
@@ -14,12 +14,20 @@ type builder struct {
 	pkg *packages.Package
 
 	muxType *types.Interface
+
+	// pkgs holds every loaded package by its path.
+	pkgs map[string]*packages.Package
 }
 
 func (b builder) astFindFile(pos token.Pos) (*ast.File, error) {
 	return astFindFile(b.pkg, pos)
 }
 
+// findPackage returns a loaded package by its path, nil if there's none.
+func (b builder) findPackage(path string) *packages.Package {
+	return b.pkgs[path]
+}
+
 func (b builder) Service(ms *types.MethodSet, hs *types.Named, fset *token.FileSet) (*serviceDesc, error) {
 	hdlRegFuncRef := ms.Lookup(b.pkg.Types, "RegisterHTTP")
 	if hdlRegFuncRef == nil {
@@ -47,17 +55,29 @@ func (b builder) Service(ms *types.MethodSet, hs *types.Named, fset *token.FileS
 	hd := []hdlDesc{}
 
 	for _, hp := range hpp {
-		fnDesc, err := b.getHandleDesc(hp.fn.Sel, ms)
+		fnDesc, err := b.getHandleDesc(hp.fn.Sel, ms, hp.sig)
 		if err != nil {
 			return nil, errors.Wrapf(err, "when parsing '%v' '%v' of '%v'", hp.op, hp.path, hs.String())
 		}
 
+		if hp.websocket != (fnDesc.socket != nil) {
+			return nil, errors.Errorf("'%v' '%v' of '%v': WebSocket handlers should be registered by WebSocket only", hp.op, hp.path, hs.String())
+		}
+
+		route, err := b.getRouteOptions(hp.opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "when parsing options of '%v' '%v' of '%v'", hp.op, hp.path, hs.String())
+		}
+		route.middlewares = route.middlewares || hp.middlewares
+		route.optsErr = hp.optsErr
+
 		hd = append(hd, hdlDesc{
 			httpVerb:    hp.op,
 			path:        hp.path,
 			inout:       *fnDesc,
 			description: fnDesc.description,
 			goFuncName:  hp.fn.Sel.Name,
+			route:       *route,
 		})
 	}
 	ret := serviceDesc{
@@ -67,8 +87,25 @@ func (b builder) Service(ms *types.MethodSet, hs *types.Named, fset *token.FileS
 		filename:          tokfile.Name(),
 		serviceStructName: hs.Obj().Name(),
 	}
-	if doc != nil {
-		ret.doc = doc.Text()
+	ret.options, err = b.getServiceOptions(ms)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse service options")
+	}
+	ret.problemDetails = hasOption(ret.options, "WithProblemDetails")
+	ret.mediaTypes, err = b.getMediaTypes(ret.options)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse media types")
+	}
+	ret.securitySchemes, ret.security, err = b.getSecurity(ret.options)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse security options")
+	}
+
+	var dirs []directive
+	ret.doc, dirs = parseDoc(doc)
+	ret.responses, err = b.getResponses(dirs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing directives of '%v'", hs.String())
 	}
 
 	return &ret, nil
@@ -78,4 +115,15 @@ type hdlPathPtr struct {
 	op   string
 	path string
 	fn   *ast.SelectorExpr
+	// sig is the handler's signature instantiated by sdesc.Handle,
+	// nil for MethodFunc.
+	sig *types.Signature
+	// opts are RouteOptions passed to MethodFunc.
+	opts []optCall
+	// optsErr is set if some of the RouteOptions can't be resolved.
+	optsErr error
+	// websocket is true if the handler is registered by WebSocket.
+	websocket bool
+	// middlewares is true if the handler's group has middlewares.
+	middlewares bool
 }
@@ -1,5 +1,7 @@
 package main
 
+import "go/types"
+
 type serviceDesc struct {
 	name string
 	doc  string
@@ -9,6 +11,20 @@ type serviceDesc struct {
 	serviceStructName string
 
 	handlers []hdlDesc
+	// responses are documented for every handler.
+	responses []respDesc
+
+	// options are sdesc calls found in ServiceOptions().
+	options []optCall
+	// problemDetails is true if errors are RFC 7807 problems.
+	problemDetails bool
+	// mediaTypes are set by sdesc.WithMediaTypes,
+	// nil means application/json.
+	mediaTypes []string
+
+	securitySchemes map[string]schemeDesc
+	// security is a default security requirement of the handlers.
+	security []securityDesc
 }
 
 type hdlDesc struct {
@@ -17,11 +33,61 @@ type hdlDesc struct {
 	path        string
 	description string
 	inout       hdlTypesDesc
+	route       routeDesc
+}
+
+// routeDesc holds RouteOptions of a handler.
+type routeDesc struct {
+	tags        []string
+	operationID string
+	deprecated  bool
+	security    []securityDesc
+	noSecurity  bool
+	hasTimeout  bool
+	// timeout is a constant timeout in nanoseconds, 0 if it's not constant.
+	timeout int64
+	// dynamicTimeout is true if the timeout is not a constant.
+	dynamicTimeout bool
+	// middlewares is true if the route or its group has middlewares.
+	middlewares bool
+	// origins are allowed to open the WebSocket.
+	origins []string
+	// optsErr is set if some of the options can't be resolved;
+	// the spec misses them.
+	optsErr error
+}
+
+// securityDesc is a security requirement.
+type securityDesc struct {
+	scheme string
+	scopes []string
 }
 
 type hdlTypesDesc struct {
+	// sig is the handler's signature.
+	sig *types.Signature
+
 	inType            *typeDesc
 	hasResponseWriter bool
 	outType           *typeDesc
 	description       string
+	responses         []respDesc
+
+	// stream is true if the output is a channel of outType.
+	stream bool
+	// socket describes the messages of a WebSocket handler.
+	socket *socketDesc
+}
+
+// socketDesc describes the messages of a WebSocket handler.
+type socketDesc struct {
+	client *typeDesc
+	server *typeDesc
+}
+
+// respDesc is a non-200 response of a handler.
+type respDesc struct {
+	code int
+	// t is a body type; nil means a default error body.
+	t *typeDesc
 }
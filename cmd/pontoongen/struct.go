@@ -11,6 +11,8 @@ import (
 )
 
 func (b *builder) getTypeDescCached(tt types.Type) (*typeDesc, error) {
+	// aliases, i.e. any, are described by their types
+	tt = types.Unalias(tt)
 	if r, ok := typeCache[tt]; ok {
 		return r, nil
 	}
@@ -29,7 +31,7 @@ func (b *builder) getTypeDesc(tt types.Type) (*typeDesc, error) {
 	case *types.Basic:
 		return &typeDesc{isScalar: true, id: t.Name(), typeName: t.Name()}, nil
 	case *types.Chan:
-		return nil, nil
+		return nil, errors.Errorf("channel '%v' can only be a handler's result", t.String())
 	case *types.Slice:
 		ut, err := b.getTypeDescCached(t.Elem())
 		if err != nil {
@@ -71,7 +73,7 @@ func (b *builder) getTypeDesc(tt types.Type) (*typeDesc, error) {
 	case *types.Named:
 		switch tu := t.Underlying().(type) {
 		case *types.Basic:
-			return b.getTypeDescCached(tu)
+			return b.getEnumDesc(t, tu)
 		case *types.Struct:
 		case *types.Map:
 			return b.getTypeDescCached(tu)
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// optCall is a call of an sdesc func found in ServiceOptions(),
+// i.e. sdesc.WithProblemDetails().
+type optCall struct {
+	name string
+	call *ast.CallExpr
+}
+
+// getServiceOptions scans ServiceOptions' function body
+// and returns the calls of sdesc's functions.
+func (b builder) getServiceOptions(ms *types.MethodSet) ([]optCall, error) {
+	sel := ms.Lookup(b.pkg.Types, "ServiceOptions")
+	if sel == nil {
+		return nil, errors.New("ServiceOptions func was not located")
+	}
+	fn := sel.Obj().(*types.Func)
+
+	af, err := b.astFindFile(fn.Pos())
+	if err != nil {
+		return nil, err
+	}
+
+	var body *ast.BlockStmt
+	path, _ := astutil.PathEnclosingInterval(af, fn.Pos(), fn.Pos())
+	for _, n := range path {
+		if fd, ok := n.(*ast.FuncDecl); ok && fd.Name.Pos() == fn.Pos() {
+			body = fd.Body
+			break
+		}
+	}
+	if body == nil {
+		return nil, errors.New("cannot find ServiceOptions' body")
+	}
+
+	ret := []optCall{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		f, ok := typeutil.Callee(b.pkg.TypesInfo, call).(*types.Func)
+		if ok && f.Pkg() != nil && f.Pkg().Path() == descPkgName {
+			ret = append(ret, optCall{name: f.Name(), call: call})
+		}
+		return true
+	})
+	return ret, nil
+}
+
+func hasOption(opts []optCall, name string) bool {
+	for _, o := range opts {
+		if o.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getMediaTypes returns the media types set by sdesc.WithMediaTypes.
+func (b builder) getMediaTypes(opts []optCall) ([]string, error) {
+	var ret []string
+	for _, o := range opts {
+		if o.name != "WithMediaTypes" {
+			continue
+		}
+		mts, err := b.constStrings(o.call.Args)
+		if err != nil {
+			return nil, errors.Wrap(err, o.name)
+		}
+		ret = append(ret, mts...)
+	}
+	return ret, nil
+}
+
+// contentTypes returns the media types of the Service's bodies.
+func (s serviceDesc) contentTypes() []string {
+	if len(s.mediaTypes) == 0 {
+		return []string{"application/json"}
+	}
+	return s.mediaTypes
+}
@@ -0,0 +1,13 @@
+// Package dep is a dependency of errdeps; the errors
+// it returns aren't the ones of errdeps' handlers.
+package dep
+
+import "github.com/utrack/pontoon/sdesc"
+
+// Lock returns a Conflict if the resource is locked.
+func Lock(id string) error {
+	if id == "" {
+		return sdesc.Conflict("locked")
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+module example.com/dep
+
+go 1.22
+
+require github.com/utrack/pontoon v0.0.0
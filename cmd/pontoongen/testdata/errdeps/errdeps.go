@@ -0,0 +1,35 @@
+// Package errdeps has a handler calling into a dependency
+// and into a helper of its own module.
+package errdeps
+
+import (
+	"net/http"
+
+	"example.com/dep"
+	"github.com/utrack/pontoon/sdesc"
+)
+
+type Handler struct{}
+
+type getRequest struct {
+	ID string `in:"path=id"`
+}
+
+func (h Handler) getItem(r *http.Request, req getRequest) error {
+	if err := dep.Lock(req.ID); err != nil {
+		return err
+	}
+	return find(req.ID)
+}
+
+func find(id string) error {
+	return sdesc.NotFound("no such item")
+}
+
+func (h Handler) ServiceOptions() []sdesc.ServiceOption {
+	return nil
+}
+
+func (h Handler) RegisterHTTP(mux sdesc.HTTPRouter) {
+	mux.MethodFunc(http.MethodGet, "/items/{id}", h.getItem)
+}
@@ -0,0 +1,24 @@
+module example.com/errdeps
+
+go 1.22
+
+require (
+	example.com/dep v0.0.0
+	github.com/utrack/pontoon v0.0.0
+)
+
+require (
+	github.com/getkin/kin-openapi v0.80.0 // indirect
+	github.com/ghodss/yaml v1.0.0 // indirect
+	github.com/go-openapi/jsonpointer v0.19.5 // indirect
+	github.com/go-openapi/swag v0.19.5 // indirect
+	github.com/mailru/easyjson v0.0.0-20190626092158-b2ccc519800e // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/net v0.21.0 // indirect
+	gopkg.in/yaml.v2 v2.3.0 // indirect
+)
+
+replace (
+	example.com/dep => ./dep
+	github.com/utrack/pontoon => ../../../..
+)
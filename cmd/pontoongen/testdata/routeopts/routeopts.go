@@ -0,0 +1,41 @@
+// Package routeopts registers routes with RouteOptions
+// pontoongen can't resolve.
+package routeopts
+
+import (
+	"net/http"
+
+	"github.com/utrack/pontoon/sdesc"
+)
+
+type Handler struct{}
+
+type item struct {
+	ID string `json:"id"`
+}
+
+func (h Handler) getItem(r *http.Request) (*item, error) {
+	return nil, nil
+}
+
+func (h Handler) ServiceOptions() []sdesc.ServiceOption {
+	return []sdesc.ServiceOption{
+		sdesc.WithSecurityScheme("jwt", sdesc.BearerAuth("JWT")),
+	}
+}
+
+var secured = sdesc.WithRouteSecurity("jwt")
+
+var securedOpts = []sdesc.RouteOption{sdesc.WithRouteSecurity("jwt")}
+
+func securedOpt() sdesc.RouteOption {
+	return sdesc.WithRouteSecurity("jwt")
+}
+
+func (h Handler) RegisterHTTP(mux sdesc.HTTPRouter) {
+	mux.MethodFunc(http.MethodGet, "/direct", h.getItem,
+		sdesc.WithRouteSecurity("jwt"))
+	mux.MethodFunc(http.MethodGet, "/variable", h.getItem, secured)
+	mux.MethodFunc(http.MethodGet, "/spread", h.getItem, securedOpts...)
+	mux.MethodFunc(http.MethodGet, "/helper", h.getItem, securedOpt())
+}
@@ -0,0 +1,61 @@
+// Package tstypes returns a type of every TypeScript mapping.
+package tstypes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/utrack/pontoon/sdesc"
+)
+
+type Handler struct{}
+
+// Color is a color of a shape.
+type Color string
+
+const (
+	ColorRed  Color = "red"
+	ColorBlue Color = "blue"
+)
+
+// Layer is an order of the shapes.
+type Layer int
+
+const (
+	LayerBack Layer = iota
+	LayerFront
+)
+
+type base struct {
+	ID string `json:"id"`
+}
+
+// shape is a figure on a canvas.
+type shape struct {
+	base
+
+	Name string `json:"name"`
+	// Parent is the shape's group, if any.
+	Parent   *shape            `json:"parent"`
+	Note     *string           `json:"note,omitempty"`
+	Color    Color             `json:"color"`
+	Layer    *Layer            `json:"layer"`
+	Palette  []Color           `json:"palette"`
+	Tags     map[string]string `json:"tags"`
+	Children map[string]*shape `json:"children"`
+	Data     []byte            `json:"data"`
+	Created  time.Time         `json:"created"`
+	Secret   string            `json:"-"`
+}
+
+func (h Handler) getShape(r *http.Request) (*shape, error) {
+	return nil, nil
+}
+
+func (h Handler) ServiceOptions() []sdesc.ServiceOption {
+	return nil
+}
+
+func (h Handler) RegisterHTTP(mux sdesc.HTTPRouter) {
+	mux.MethodFunc(http.MethodGet, "/shape", h.getShape)
+}
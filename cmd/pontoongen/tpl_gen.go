@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"go/format"
 	"text/template"
 )
 
@@ -10,6 +11,11 @@ type tplRequest struct {
 	PkgName           string
 	Content           string
 	HandlerStructName string
+
+	// Imports are import specs used by Bindings.
+	Imports []string
+	// Bindings is a generated RegisterHTTPHandlers.
+	Bindings string
 }
 
 var tpl = template.Must(
@@ -18,11 +24,19 @@ var tpl = template.Must(
 // Source: {{ .PkgPath }}
 
 package {{ .PkgName }}
-
+{{ if .Imports }}
+import (
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
+)
+{{ end }}
 func (s {{ .HandlerStructName }}) OpenAPI() string {
 	return ` + "`{{ .Content }}`" + `
 }
-`,
+{{ if .Bindings }}
+{{ .Bindings }}
+{{- end }}`,
 	))
 
 func tplGen(req tplRequest) ([]byte, error) {
@@ -30,5 +44,44 @@ func tplGen(req tplRequest) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 
 	err := tpl.Execute(buf, req)
-	return buf.Bytes(), err
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+type tplClientRequest struct {
+	PkgPath string
+	PkgName string
+
+	// Imports are import specs used by Client.
+	Imports []string
+	// Client is a generated client of the service.
+	Client string
+}
+
+var clientTpl = template.Must(
+	template.New("").Parse(
+		`// Code generated by utrack/pontoon. DO NOT EDIT.
+// Source: {{ .PkgPath }}
+
+package {{ .PkgName }}
+
+import (
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
+)
+
+{{ .Client }}`,
+	))
+
+func tplGenClient(req tplClientRequest) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	err := clientTpl.Execute(buf, req)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
 }
@@ -0,0 +1,841 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+)
+
+// tsGen generates TypeScript definitions of a service's types
+// and a fetch-based client with a function per operation.
+// The interfaces describe JSON as encoding/json marshals it.
+type tsGen struct {
+	pkgName string
+	buf     *bytes.Buffer
+
+	// types are the structs in order of their first use.
+	types []*typeDesc
+	// names are TypeScript names of the structs by typeDesc.id.
+	names map[string]string
+}
+
+// genTypeScript generates the TypeScript module of the service.
+func genTypeScript(s serviceDesc, pkgPath, pkgName string) ([]byte, error) {
+	g := &tsGen{
+		pkgName: pkgName,
+		buf:     bytes.NewBuffer(nil),
+		names:   map[string]string{},
+	}
+
+	seen := map[string]bool{}
+	for _, h := range s.handlers {
+		in := h.inout.inType
+		if in != nil && in.isPtr != nil {
+			in = in.isPtr
+		}
+		if in != nil && h.inout.socket == nil {
+			if tsHasParams(in) {
+				g.collectParams(in, seen)
+			} else {
+				g.collect(in, seen)
+			}
+		}
+		g.collect(h.inout.outType, seen)
+		if sd := h.inout.socket; sd != nil {
+			g.collect(sd.client, seen)
+			g.collect(sd.server, seen)
+		}
+		for _, r := range h.inout.responses {
+			g.collect(r.t, seen)
+		}
+	}
+	for _, r := range s.responses {
+		g.collect(r.t, seen)
+	}
+	g.nameTypes()
+
+	g.p("// Code generated by utrack/pontoon. DO NOT EDIT.")
+	g.p("// Source: %v", pkgPath)
+	g.p("")
+	g.p("/* eslint-disable */")
+	g.buf.WriteString(tsRuntime)
+
+	for _, t := range g.types {
+		if err := g.genInterface(t); err != nil {
+			return nil, errors.Wrapf(err, "type '%v'", t.typeName)
+		}
+	}
+
+	done := map[string]bool{}
+	for _, h := range s.handlers {
+		in := h.inout.inType
+		if in == nil || h.inout.socket != nil {
+			continue
+		}
+		if in.isPtr != nil {
+			in = in.isPtr
+		}
+		if !tsHasParams(in) || done[in.id] {
+			continue
+		}
+		done[in.id] = true
+		if err := g.genParams(in); err != nil {
+			return nil, errors.Wrapf(err, "parameters of '%v'", in.typeName)
+		}
+	}
+
+	uses := map[string]int{}
+	for _, h := range s.handlers {
+		uses[h.goFuncName]++
+	}
+	for _, h := range s.handlers {
+		if h.inout.socket != nil {
+			continue
+		}
+		name := strcase.ToLowerCamel(h.goFuncName)
+		if uses[h.goFuncName] > 1 {
+			name = strcase.ToLowerCamel(handlerOperationID(h))
+		}
+		if err := g.genOperation(s, h, name); err != nil {
+			return nil, errors.Wrapf(err, "handler '%v'", h.goFuncName)
+		}
+	}
+	return g.buf.Bytes(), nil
+}
+
+func (g *tsGen) p(format string, args ...interface{}) {
+	fmt.Fprintf(g.buf, format+"\n", args...)
+}
+
+// collect registers the structs reachable from the JSON of t.
+func (g *tsGen) collect(t *typeDesc, seen map[string]bool) {
+	switch {
+	case t == nil:
+	case t.isPtr != nil:
+		g.collect(t.isPtr, seen)
+	case t.isSlice != nil:
+		g.collect(t.isSlice.t, seen)
+	case t.isMap != nil:
+		g.collect(t.isMap.value, seen)
+	case t.isStruct != nil:
+		if seen[t.id] {
+			return
+		}
+		seen[t.id] = true
+		g.types = append(g.types, t)
+		for _, e := range t.isStruct.embeds {
+			g.collect(e.t, seen)
+		}
+		for _, f := range t.isStruct.fields {
+			if !tsInJSON(f) {
+				continue
+			}
+			g.collect(f.t, seen)
+		}
+	}
+}
+
+// collectParams registers the structs of the input's bodies.
+func (g *tsGen) collectParams(t *typeDesc, seen map[string]bool) {
+	for _, e := range t.isStruct.embeds {
+		et := e.t
+		if et.isPtr != nil {
+			et = et.isPtr
+		}
+		if et.isStruct != nil {
+			g.collectParams(et, seen)
+		}
+	}
+	for _, f := range t.isStruct.fields {
+		if props := genInProps(f.tags); props != nil && props.location == lBody {
+			g.collect(f.t, seen)
+		}
+	}
+}
+
+// nameTypes names the structs after their Go types,
+// prefixing the names that clash with their packages.
+func (g *tsGen) nameTypes() {
+	short := map[string]int{}
+	for _, t := range g.types {
+		_, n := tsSplitTypeName(t.typeName)
+		short[n]++
+	}
+	for _, t := range g.types {
+		pkg, n := tsSplitTypeName(t.typeName)
+		if short[n] > 1 && pkg != g.pkgName {
+			n = strcase.ToCamel(pkg) + n
+		}
+		g.names[t.id] = n
+	}
+}
+
+var tsNonIdent = regexp.MustCompile(`[^A-Za-z0-9_$]+`)
+
+// tsSplitTypeName splits 'pkg.Type' into the package
+// and the TypeScript type name.
+func tsSplitTypeName(typeName string) (string, string) {
+	pkg, name, ok := strings.Cut(typeName, ".")
+	if !ok {
+		name, pkg = pkg, ""
+	}
+	name = tsNonIdent.ReplaceAllString(name, "_")
+	return pkg, strcase.ToCamel(strings.Trim(name, "_"))
+}
+
+// tsHasParams is true if the input is bound from several locations
+// rather than being a JSON body.
+func tsHasParams(t *typeDesc) bool {
+	if t.isStruct == nil {
+		return false
+	}
+	for _, f := range append(append([]descField{}, t.isStruct.fields...), t.isStruct.embeds...) {
+		if props := genInProps(f.tags); props != nil && props.location != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// tsInJSON is true if the field is marshaled as JSON.
+func tsInJSON(f descField) bool {
+	if f.name == "" || !unicode.IsUpper([]rune(f.name)[0]) {
+		return false
+	}
+	if genJSONFieldName(f.name, f.tags) == "-" {
+		return false
+	}
+	props := genInProps(f.tags)
+	if props == nil {
+		return true
+	}
+	switch props.location {
+	case lForm, lHeader, lQuery, lPath:
+		return false
+	}
+	return true
+}
+
+// tsJSONOptions returns the options of the field's json tag.
+func tsJSONOptions(tags string) []string {
+	tag := reflect.StructTag(strings.Trim(tags, "`")).Get("json")
+	opts := strings.Split(tag, ",")
+	return opts[1:]
+}
+
+func hasString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// tsType returns the TypeScript type of the value's JSON.
+func (g *tsGen) tsType(t *typeDesc) string {
+	switch {
+	case t.isPtr != nil:
+		return tsNullable(g.tsType(t.isPtr))
+	case t.isScalar && len(t.enum) > 0:
+		return tsEnum(t.enum)
+	case t.isScalar:
+		return tsScalar(t)
+	case t.isAny:
+		return "unknown"
+	case t.isSpecial == specialTypeTime:
+		return "string"
+	case t.isSpecial == specialTypeFile:
+		return "Blob"
+	case t.isSlice != nil:
+		if t.isSlice.t.typeName == "byte" || t.isSlice.t.typeName == "uint8" {
+			// base64
+			return "string"
+		}
+		return tsNullable(tsArray(g.tsType(t.isSlice.t)))
+	case t.isMap != nil:
+		return "Record<string, " + g.tsType(t.isMap.value) + ">"
+	case t.isStruct != nil:
+		return g.names[t.id]
+	}
+	return "unknown"
+}
+
+func tsScalar(t *typeDesc) string {
+	switch t.typeName {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	}
+	return "number"
+}
+
+// tsEnum returns the union of the enum's literal values.
+func tsEnum(enum []descEnum) string {
+	vs := make([]string, 0, len(enum))
+	for _, e := range enum {
+		v, err := json.Marshal(e.value)
+		if err != nil {
+			return "unknown"
+		}
+		vs = append(vs, string(v))
+	}
+	return strings.Join(vs, " | ")
+}
+
+func tsNullable(s string) string {
+	if strings.HasSuffix(s, " | null") {
+		return s
+	}
+	return s + " | null"
+}
+
+func tsArray(s string) string {
+	if strings.Contains(s, "|") {
+		return "(" + s + ")[]"
+	}
+	return s + "[]"
+}
+
+var tsIdent = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsKey returns the property name, quoted if it's not an identifier.
+func tsKey(name string) string {
+	if tsIdent.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// tsAccess returns the expression accessing the object's property.
+func tsAccess(obj, name string) string {
+	if tsIdent.MatchString(name) {
+		return obj + "." + name
+	}
+	return fmt.Sprintf("%v[%q]", obj, name)
+}
+
+// tsDoc writes the comment as JSDoc.
+func (g *tsGen) tsDoc(indent, doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	doc = strings.ReplaceAll(doc, "*/", "*\\/")
+	lines := strings.Split(doc, "\n")
+	if len(lines) == 1 {
+		g.p("%v/** %v */", indent, lines[0])
+		return
+	}
+	g.p("%v/**", indent)
+	for _, l := range lines {
+		g.p("%v%v", indent, strings.TrimRight(" * "+l, " "))
+	}
+	g.p("%v */", indent)
+}
+
+// genInterface describes the struct's JSON.
+func (g *tsGen) genInterface(t *typeDesc) error {
+	name := g.names[t.id]
+	extends := []string{}
+	type prop struct {
+		key, typ, doc string
+		optional      bool
+	}
+	props := []prop{}
+
+	for _, e := range t.isStruct.embeds {
+		jsonName := genJSONFieldName("", e.tags)
+		et := e.t
+		if et.isPtr != nil {
+			et = et.isPtr
+		}
+		if jsonName != "" && jsonName != "-" {
+			// tagged embeds aren't flattened
+			props = append(props, prop{key: jsonName, typ: g.tsType(e.t), doc: e.doc,
+				optional: hasString(tsJSONOptions(e.tags), "omitempty")})
+			continue
+		}
+		if et.isStruct == nil || jsonName == "-" {
+			continue
+		}
+		if e.t.isPtr != nil {
+			// nil embeds omit their fields
+			extends = append(extends, "Partial<"+g.names[et.id]+">")
+			continue
+		}
+		extends = append(extends, g.names[et.id])
+	}
+
+	for _, f := range t.isStruct.fields {
+		if !tsInJSON(f) {
+			continue
+		}
+		opts := tsJSONOptions(f.tags)
+		typ := g.tsType(f.t)
+		if hasString(opts, "string") {
+			// quoted scalars
+			ft := f.t
+			if ft.isPtr != nil {
+				ft = ft.isPtr
+			}
+			if ft.isScalar {
+				typ = "string"
+				if f.t.isPtr != nil {
+					typ = tsNullable(typ)
+				}
+			}
+		}
+		jsonName := genJSONFieldName(f.name, f.tags)
+		props = append(props, prop{
+			key:      jsonName,
+			typ:      typ,
+			doc:      docFromComment(f.name, jsonName, f.doc),
+			optional: hasString(opts, "omitempty") || hasString(opts, "omitzero"),
+		})
+	}
+
+	g.p("")
+	g.tsDoc("", docFromComment(t.typeName, "", t.doc))
+	decl := "export interface " + name
+	if len(extends) > 0 {
+		decl += " extends " + strings.Join(extends, ", ")
+	}
+	if len(props) == 0 {
+		g.p("%v {}", decl)
+		return nil
+	}
+	g.p("%v {", decl)
+	for _, p := range props {
+		g.tsDoc("  ", p.doc)
+		opt := ""
+		if p.optional {
+			opt = "?"
+		}
+		g.p("  %v%v: %v;", tsKey(p.key), opt, p.typ)
+	}
+	g.p("}")
+	return nil
+}
+
+// tsParam is a value of the operation's request.
+type tsParam struct {
+	key      string
+	location string
+	// name is the parameter's name in its location.
+	name     string
+	doc      string
+	t        *typeDesc
+	required bool
+}
+
+// params lists the input's values, flattening the embedded structs.
+func (g *tsGen) params(t *typeDesc, optional bool) []tsParam {
+	ret := []tsParam{}
+	for _, e := range t.isStruct.embeds {
+		et := e.t
+		if et.isPtr != nil {
+			et = et.isPtr
+		}
+		if et.isStruct != nil {
+			ret = append(ret, g.params(et, optional || e.t.isPtr != nil)...)
+		}
+	}
+	for _, f := range t.isStruct.fields {
+		props := genInProps(f.tags)
+		if props == nil || props.location == "" {
+			continue
+		}
+		p := tsParam{
+			key:      props.name,
+			location: props.location,
+			name:     props.name,
+			doc:      docFromComment(f.name, props.name, f.doc),
+			t:        f.t,
+			required: !optional && (props.location == lPath || props.required),
+		}
+		if props.location == lBody {
+			p.key = genJSONFieldName(f.name, f.tags)
+			p.required = !optional && props.required
+		}
+		if props.defValue != "" {
+			p.doc = strings.TrimSpace(p.doc + "\n\nDefaults to " + props.defValue + ".")
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+func (g *tsGen) paramsName(t *typeDesc) string {
+	_, n := tsSplitTypeName(t.typeName)
+	return n + "Params"
+}
+
+// paramType returns the type of the request's value.
+func (g *tsGen) paramType(p tsParam) string {
+	if p.location == lBody {
+		return g.tsType(p.t)
+	}
+	t := p.t
+	if t.isPtr != nil {
+		t = t.isPtr
+	}
+	switch {
+	case t.isSpecial == specialTypeTime:
+		return "Date | string"
+	case t.isSlice != nil && t.isSlice.t.typeName != "byte" && t.isSlice.t.typeName != "uint8":
+		return tsArray(g.paramType(tsParam{t: t.isSlice.t}))
+	}
+	return g.tsType(t)
+}
+
+// genParams describes the request's values bound by the input.
+func (g *tsGen) genParams(t *typeDesc) error {
+	keys := map[string]bool{}
+	g.p("")
+	g.tsDoc("", docFromComment(t.typeName, "", t.doc))
+	g.p("export interface %v {", g.paramsName(t))
+	for _, p := range g.params(t, false) {
+		if keys[p.key] {
+			return errors.Errorf("'%v' is bound more than once", p.key)
+		}
+		keys[p.key] = true
+		g.tsDoc("  ", p.doc)
+		opt := "?"
+		if p.required {
+			opt = ""
+		}
+		g.p("  %v%v: %v;", tsKey(p.key), opt, g.paramType(p))
+	}
+	g.p("}")
+	return nil
+}
+
+// tsPath returns the expression of the request's path;
+// params are the expressions of the path parameters.
+func tsPath(pattern string, params map[string]string) (string, error) {
+	parts := []string{}
+	lit := ""
+	for {
+		i := strings.IndexByte(pattern, '{')
+		j := strings.IndexByte(pattern, '}')
+		if i < 0 || j < i {
+			lit += pattern
+			break
+		}
+		lit += pattern[:i]
+		name := pattern[i+1 : j]
+		pattern = pattern[j+1:]
+		if name == "$" {
+			continue
+		}
+		format := "pathParam(%v)"
+		if n, ok := strings.CutSuffix(name, "..."); ok {
+			name, format = n, "pathRest(%v)"
+		}
+		v, ok := params[name]
+		if !ok {
+			return "", errors.Errorf("path parameter '%v' isn't bound by the input", name)
+		}
+		parts = append(parts, fmt.Sprintf("%q", lit), fmt.Sprintf(format, v))
+		lit = ""
+	}
+	if lit != "" || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", lit))
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+// genOperation generates the function calling the handler.
+func (g *tsGen) genOperation(s serviceDesc, h hdlDesc, name string) error {
+	in := h.inout.inType
+	if in != nil && in.isPtr != nil {
+		in = in.isPtr
+	}
+
+	args := []string{"cfg: ClientConfig"}
+	call := []string{fmt.Sprintf("method: %q", h.httpVerb)}
+	pathParams := map[string]string{}
+	if in != nil && tsHasParams(in) {
+		args = append(args, "params: "+g.paramsName(in))
+		groups := map[string][]string{}
+		multipart := false
+		for _, p := range g.params(in, false) {
+			access := tsAccess("params", p.key)
+			switch p.location {
+			case lBody:
+				call = append(call, "body: "+access)
+			case lPath:
+				pathParams[p.name] = access
+			default:
+				groups[p.location] = append(groups[p.location], tsKey(p.name)+": "+access)
+				pt := p.t
+				if pt.isPtr != nil {
+					pt = pt.isPtr
+				}
+				multipart = multipart || pt.isSpecial == specialTypeFile
+			}
+		}
+		for _, loc := range []string{lQuery, lHeader, lForm} {
+			vs, ok := groups[loc]
+			if !ok {
+				continue
+			}
+			key := map[string]string{lQuery: "query", lHeader: "headers", lForm: "form"}[loc]
+			call = append(call, key+": { "+strings.Join(vs, ", ")+" }")
+		}
+		if multipart {
+			call = append(call, "multipart: true")
+		}
+	} else if in != nil {
+		args = append(args, "body: "+g.tsType(in))
+		call = append(call, "body")
+	}
+	args = append(args, "init?: RequestInit")
+
+	path, err := tsPath(h.path, pathParams)
+	if err != nil {
+		return err
+	}
+	call = append([]string{call[0], "path: " + path}, call[1:]...)
+
+	var result, ret string
+	fn := "async function"
+	switch {
+	case h.inout.hasResponseWriter:
+		result = "Promise<Response>"
+	case h.inout.stream:
+		fn = "async function*"
+		result = "AsyncGenerator<" + g.tsType(h.inout.outType) + ">"
+		ret = "yield* readNDJSON<" + g.tsType(h.inout.outType) + ">(rsp);"
+		call = append(call, fmt.Sprintf("accept: %q", "application/x-ndjson"))
+	case h.inout.outType != nil:
+		t := g.tsType(h.inout.outType)
+		if _, ok := h.inout.sig.Results().At(0).Type().(*types.Pointer); ok {
+			t = tsNullable(t)
+		}
+		result, ret = "Promise<"+t+">", "return (await rsp.json()) as "+t+";"
+	default:
+		result = "Promise<void>"
+	}
+
+	doc := docFromComment(h.goFuncName, "", h.description)
+	if doc != "" {
+		doc += "\n\n"
+	}
+	doc += fmt.Sprintf("`%v %v`\n@throws {ApiError} if the call fails", h.httpVerb, h.path)
+	for _, r := range errorBodies(s, h) {
+		doc += fmt.Sprintf(";\n  a %v error's body is %v", r.code, g.tsType(r.t))
+	}
+	doc += "."
+	if h.inout.hasResponseWriter {
+		doc += "\nThe response's body is left unread."
+	}
+
+	g.p("")
+	g.tsDoc("", doc)
+	g.p("export %v %v(%v): %v {", fn, name, strings.Join(args, ", "), result)
+	switch {
+	case h.inout.hasResponseWriter:
+		g.p("  return call(cfg, {")
+	case ret != "":
+		g.p("  const rsp = await call(cfg, {")
+	default:
+		g.p("  await call(cfg, {")
+	}
+	for _, c := range call {
+		g.p("    %v,", c)
+	}
+	g.p("  }, init);")
+	if ret != "" {
+		g.p("  %v", ret)
+	}
+	g.p("}")
+	return nil
+}
+
+// tsRuntime is the client's runtime shared by the operations.
+const tsRuntime = `
+/** ClientConfig points the operations to the service. */
+export interface ClientConfig {
+  /** Base URL of the service, i.e. "https://api.example.com". */
+  baseURL: string;
+  /** Replaces the global fetch. */
+  fetch?: typeof fetch;
+  /** Is applied to every request, i.e. to set the credentials. */
+  init?: RequestInit;
+}
+
+/** FieldError describes a single invalid value of the request. */
+export interface FieldError {
+  field: string;
+  in: string;
+  name: string;
+  reason: string;
+}
+
+/** ApiError is thrown when the service responds with an error. */
+export class ApiError extends Error {
+  /** HTTP status code of the response. */
+  readonly status: number;
+  /** Decoded JSON body of the response, or its text. */
+  readonly body: unknown;
+  /** Invalid values of the request, if any. */
+  readonly fields: FieldError[];
+
+  constructor(status: number, body: unknown) {
+    super(errorMessage(status, body));
+    this.name = "ApiError";
+    this.status = status;
+    this.body = body;
+    const b = (typeof body === "object" && body !== null ? body : {}) as Record<string, unknown>;
+    this.fields = (b.fields ?? b["invalid-params"] ?? []) as FieldError[];
+  }
+}
+
+function errorMessage(status: number, body: unknown): string {
+  if (typeof body === "object" && body !== null) {
+    const b = body as Record<string, unknown>;
+    const msg = b.error ?? b.detail ?? b.title;
+    if (typeof msg === "string") {
+      return status + ": " + msg;
+    }
+  }
+  if (typeof body === "string" && body !== "") {
+    return status + ": " + body;
+  }
+  return String(status);
+}
+
+interface Call {
+  method: string;
+  path: string;
+  query?: Record<string, unknown>;
+  headers?: Record<string, unknown>;
+  form?: Record<string, unknown>;
+  multipart?: boolean;
+  body?: unknown;
+  accept?: string;
+}
+
+/** values formats the parameter; unset values are left to the service's defaults. */
+function values(v: unknown): string[] {
+  if (v === undefined || v === null) {
+    return [];
+  }
+  if (Array.isArray(v)) {
+    return v.flatMap(values);
+  }
+  if (v instanceof Date) {
+    return [v.toISOString()];
+  }
+  return [String(v)];
+}
+
+function pathParam(v: unknown): string {
+  return encodeURIComponent(values(v).join(","));
+}
+
+function pathRest(v: unknown): string {
+  return values(v).join(",").split("/").map(encodeURIComponent).join("/");
+}
+
+async function call(cfg: ClientConfig, c: Call, init?: RequestInit): Promise<Response> {
+  const url = new URL(cfg.baseURL.replace(/\/+$/, "") + c.path);
+  for (const [k, v] of Object.entries(c.query ?? {})) {
+    for (const s of values(v)) {
+      url.searchParams.append(k, s);
+    }
+  }
+
+  const headers = new Headers(cfg.init?.headers);
+  new Headers(init?.headers).forEach((v, k) => headers.set(k, v));
+  for (const [k, v] of Object.entries(c.headers ?? {})) {
+    for (const s of values(v)) {
+      headers.append(k, s);
+    }
+  }
+  headers.set("Accept", c.accept ?? "application/json");
+
+  let body: BodyInit | undefined;
+  if (c.form !== undefined && c.multipart) {
+    const form = new FormData();
+    for (const [k, v] of Object.entries(c.form)) {
+      if (v instanceof Blob) {
+        form.append(k, v);
+        continue;
+      }
+      for (const s of values(v)) {
+        form.append(k, s);
+      }
+    }
+    body = form;
+  } else if (c.form !== undefined) {
+    const form = new URLSearchParams();
+    for (const [k, v] of Object.entries(c.form)) {
+      for (const s of values(v)) {
+        form.append(k, s);
+      }
+    }
+    body = form;
+  } else if (c.body !== undefined) {
+    headers.set("Content-Type", "application/json");
+    body = JSON.stringify(c.body);
+  }
+
+  const rsp = await (cfg.fetch ?? fetch)(url, { ...cfg.init, ...init, method: c.method, headers, body });
+  if (!rsp.ok) {
+    const text = await rsp.text();
+    let errBody: unknown = text;
+    try {
+      errBody = JSON.parse(text);
+    } catch {
+      // not a JSON body
+    }
+    throw new ApiError(rsp.status, errBody);
+  }
+  return rsp;
+}
+
+/** readNDJSON yields the elements of a streamed response. */
+async function* readNDJSON<T>(rsp: Response): AsyncGenerator<T> {
+  if (rsp.body === null) {
+    return;
+  }
+  const reader = rsp.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      buf += decoder.decode(value, { stream: !done });
+      let i: number;
+      while ((i = buf.indexOf("\n")) >= 0) {
+        const line = buf.slice(0, i).trim();
+        buf = buf.slice(i + 1);
+        if (line !== "") {
+          yield JSON.parse(line) as T;
+        }
+      }
+      if (done) {
+        break;
+      }
+    }
+    if (buf.trim() !== "") {
+      yield JSON.parse(buf) as T;
+    }
+  } finally {
+    // closes the stream if the caller stops early
+    await reader.cancel();
+  }
+}
+`
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenTypeScriptTypes(t *testing.T) {
+	svc, ps := loadTestService(t, "testdata/tstypes")
+	res, err := genTypeScript(svc, ps.pkg.PkgPath, ps.pkg.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := string(res)
+
+	for _, want := range []string{
+		"/** A figure on a canvas. */\nexport interface Shape extends Base {",
+		"export interface Base {\n  id: string;\n}",
+		"  name: string;",
+		"  /** The shape's group, if any. */\n  parent: Shape | null;",
+		"  note?: string | null;",
+		`  color: "red" | "blue";`,
+		"  layer: 0 | 1 | null;",
+		`  palette: ("red" | "blue")[] | null;`,
+		"  tags: Record<string, string>;",
+		"  children: Record<string, Shape | null>;",
+		"  data: string;",
+		"  created: string;",
+		"export async function getShape(cfg: ClientConfig, init?: RequestInit): Promise<Shape | null> {",
+	} {
+		if !strings.Contains(ts, want) {
+			t.Errorf("TypeScript doesn't contain\n%v", want)
+		}
+	}
+	if strings.Contains(ts, "Secret") {
+		t.Error("TypeScript contains the field skipped by JSON")
+	}
+	if t.Failed() {
+		t.Log(ts)
+	}
+}
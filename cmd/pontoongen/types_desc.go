@@ -17,6 +17,8 @@ type typeDesc struct {
 	typeName string
 	doc      string
 	isScalar bool
+	// enum holds the constants of named scalars, if any.
+	enum []descEnum
 
 	isSpecial specialTypeType
 	isStruct  *descStruct
@@ -40,6 +42,12 @@ type descStruct struct {
 	fields []descField
 }
 
+type descEnum struct {
+	name  string
+	doc   string
+	value interface{}
+}
+
 type descField struct {
 	name string
 	doc  string
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+)
+
+// validateFormats map go-playground/validator rules to the string formats.
+var validateFormats = map[string]string{
+	"email":            "email",
+	"url":              "uri",
+	"uri":              "uri",
+	"http_url":         "uri",
+	"uuid":             "uuid",
+	"uuid3":            "uuid",
+	"uuid4":            "uuid",
+	"uuid5":            "uuid",
+	"ipv4":             "ipv4",
+	"ip4_addr":         "ipv4",
+	"ipv6":             "ipv6",
+	"ip6_addr":         "ipv6",
+	"hostname":         "hostname",
+	"hostname_rfc1123": "hostname",
+}
+
+// validatePatterns map go-playground/validator rules to the string patterns.
+var validatePatterns = map[string]string{
+	"alpha":       `^[a-zA-Z]+$`,
+	"alphanum":    `^[a-zA-Z0-9]+$`,
+	"numeric":     `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":      `^[0-9]+$`,
+	"hexadecimal": `^(0[xX])?[0-9a-fA-F]+$`,
+	"lowercase":   `^[^A-Z]*$`,
+	"uppercase":   `^[^a-z]*$`,
+}
+
+// oneofValues splits the values of oneof, i.e. "a 'b c' d".
+var oneofValues = regexp.MustCompile(`'[^']*'|\S+`)
+
+// validateRequired is true if the field's validate:"..." tag
+// requires the value.
+func validateRequired(tags string) bool {
+	for _, r := range validateRules(tags) {
+		if r == "dive" {
+			break
+		}
+		if r == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRules returns the rules of the field's validate:"..." tag.
+func validateRules(tags string) []string {
+	tag := reflect.StructTag(strings.Trim(tags, "`")).Get("validate")
+	if tag == "" || tag == "-" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// genValidate maps the common rules of the field's validate:"..." tag
+// onto the JSON Schema of its value: minimum/maximum, minLength/maxLength,
+// pattern, format, enum, minItems/maxItems and uniqueItems.
+// The rules following "dive" constrain the items of slices.
+// Unknown rules are skipped.
+func genValidate(ref *openapi3.SchemaRef, f descField) error {
+	rules := validateRules(f.tags)
+	if len(rules) == 0 || ref == nil {
+		return nil
+	}
+	err := genConstraints(ref, f.t, rules)
+	return errors.Wrapf(err, "validate tag of field '%v'", f.name)
+}
+
+func genConstraints(ref *openapi3.SchemaRef, t *typeDesc, rules []string) error {
+	if t.isPtr != nil {
+		t = t.isPtr
+	}
+	sc := ref.Value
+	if sc == nil || len(sc.AnyOf) > 0 {
+		// structs are described by their references
+		return nil
+	}
+
+	for i := 0; i < len(rules); i++ {
+		rule, param, _ := strings.Cut(rules[i], "=")
+		switch {
+		case rule == "keys":
+			// rules of the map keys aren't described
+			for i < len(rules) && rules[i] != "endkeys" {
+				i++
+			}
+			continue
+		case rule == "dive":
+			if t.isSlice == nil || sc.Items == nil {
+				return nil
+			}
+			return genConstraints(sc.Items, t.isSlice.t, rules[i+1:])
+		case strings.Contains(rules[i], "|"):
+			// alternatives aren't described
+			continue
+		}
+
+		if f, ok := validateFormats[rule]; ok && sc.Type == openapi3.TypeString {
+			sc.Format = f
+			continue
+		}
+		if p, ok := validatePatterns[rule]; ok && sc.Type == openapi3.TypeString {
+			addPattern(sc, p)
+			continue
+		}
+
+		var err error
+		switch rule {
+		case "min", "gte":
+			err = setBound(sc, param, false, false)
+		case "max", "lte":
+			err = setBound(sc, param, true, false)
+		case "gt":
+			err = setBound(sc, param, false, true)
+		case "lt":
+			err = setBound(sc, param, true, true)
+		case "len":
+			err = setBound(sc, param, false, false)
+			if err == nil {
+				err = setBound(sc, param, true, false)
+			}
+		case "oneof":
+			// the rule narrows the values of enums
+			sc.Enum = nil
+			delete(sc.Extensions, "x-enum-descriptions")
+			for _, v := range oneofValues.FindAllString(param, -1) {
+				ev, err := scalarValue(t, strings.Trim(v, "'"))
+				if err != nil {
+					return errors.Wrapf(err, "rule '%v'", rules[i])
+				}
+				sc.Enum = append(sc.Enum, ev)
+			}
+		case "unique":
+			if sc.Type == openapi3.TypeArray {
+				sc.UniqueItems = true
+			}
+		case "startswith":
+			addPattern(sc, "^"+regexp.QuoteMeta(param))
+		case "endswith":
+			addPattern(sc, regexp.QuoteMeta(param)+"$")
+		case "contains":
+			addPattern(sc, regexp.QuoteMeta(param))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "rule '%v'", rules[i])
+		}
+	}
+	return nil
+}
+
+// setBound sets the schema's minimum or maximum of the value,
+// of its length or of its items, depending on the schema's type.
+func setBound(sc *openapi3.Schema, param string, max, exclusive bool) error {
+	if sc.Type == openapi3.TypeNumber || sc.Type == openapi3.TypeInteger {
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return errors.Wrapf(err, "bad number '%v'", param)
+		}
+		if max {
+			sc.Max, sc.ExclusiveMax = &v, exclusive
+		} else {
+			sc.Min, sc.ExclusiveMin = &v, exclusive
+		}
+		return nil
+	}
+
+	v, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "bad length '%v'", param)
+	}
+	switch {
+	case exclusive && max && v == 0:
+		return errors.New("length can't be less than 0")
+	case exclusive && max:
+		v--
+	case exclusive && v < math.MaxUint64:
+		v++
+	}
+	switch {
+	case sc.Type == openapi3.TypeString && sc.Format != "byte":
+		if max {
+			sc.MaxLength = &v
+		} else {
+			sc.MinLength = v
+		}
+	case sc.Type == openapi3.TypeArray:
+		if max {
+			sc.MaxItems = &v
+		} else {
+			sc.MinItems = v
+		}
+	case sc.Type == openapi3.TypeObject:
+		if max {
+			sc.MaxProps = &v
+		} else {
+			sc.MinProps = v
+		}
+	}
+	return nil
+}
+
+// addPattern sets the schema's pattern;
+// the following ones are added as allOf.
+func addPattern(sc *openapi3.Schema, p string) {
+	if sc.Pattern == "" {
+		sc.Pattern = p
+		return
+	}
+	sc.AllOf = append(sc.AllOf, openapi3.NewSchemaRef("", &openapi3.Schema{Pattern: p}))
+}
+
+// typedDefault converts the value's default to the value of its JSON;
+// defaults of slices are their only items.
+func typedDefault(t *typeDesc, s string) (interface{}, error) {
+	if t.isPtr != nil {
+		t = t.isPtr
+	}
+	if t.isSlice != nil && t.isSlice.t.typeName != "byte" {
+		v, err := typedDefault(t.isSlice.t, s)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{v}, nil
+	}
+	if t.isScalar || t.isSpecial == specialTypeTime {
+		return scalarValue(t, s)
+	}
+	return s, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGenValidate(t *testing.T) {
+	var (
+		intT    = &typeDesc{typeName: "int", isScalar: true}
+		floatT  = &typeDesc{typeName: "float64", isScalar: true}
+		stringT = &typeDesc{typeName: "string", isScalar: true}
+		status  = &typeDesc{typeName: "string", isScalar: true, enum: []descEnum{
+			{name: "StatusNew", value: "new"},
+			{name: "StatusSale", value: "sale"},
+		}}
+		strs   = &typeDesc{isSlice: &descSlice{t: stringT}}
+		ints   = &typeDesc{isSlice: &descSlice{t: intT}}
+		labels = &typeDesc{isMap: &descMap{key: stringT, value: stringT}}
+	)
+
+	tests := []struct {
+		name string
+		t    *typeDesc
+		tag  string
+		want string
+	}{
+		{"number bounds", intT, "min=1,max=100",
+			`{"type":"integer","format":"int64","minimum":1,"maximum":100}`},
+		{"exclusive number bounds", floatT, "gt=0,lt=1",
+			`{"type":"number","format":"double","minimum":0,"maximum":1,"exclusiveMinimum":true,"exclusiveMaximum":true}`},
+		{"inclusive number bounds", floatT, "gte=0.5,lte=1.5",
+			`{"type":"number","format":"double","minimum":0.5,"maximum":1.5}`},
+		{"string length", stringT, "min=2,max=10",
+			`{"type":"string","minLength":2,"maxLength":10}`},
+		{"exclusive string length", stringT, "gt=2,lt=10",
+			`{"type":"string","minLength":3,"maxLength":9}`},
+		{"exact string length", stringT, "len=4",
+			`{"type":"string","minLength":4,"maxLength":4}`},
+		{"slice length", strs, "min=1,max=5,unique",
+			`{"type":"array","nullable":true,"items":{"type":"string"},"minItems":1,"maxItems":5,"uniqueItems":true}`},
+		{"exclusive slice length", strs, "gt=0,lt=5",
+			`{"type":"array","nullable":true,"items":{"type":"string"},"minItems":1,"maxItems":4}`},
+		{"oneof", stringT, "oneof=new sale 'top rated'",
+			`{"type":"string","enum":["new","sale","top rated"]}`},
+		{"numeric oneof", intT, "oneof=1 2 3",
+			`{"type":"integer","format":"int64","enum":[1,2,3]}`},
+		{"oneof narrows enum", status, "oneof=sale",
+			`{"type":"string","enum":["sale"]}`},
+		{"dive", strs, "max=10,dive,min=1,alpha",
+			`{"type":"array","nullable":true,"items":{"type":"string","minLength":1,"pattern":"^[a-zA-Z]+$"},"maxItems":10}`},
+		{"dive into numbers", ints, "dive,gte=0",
+			`{"type":"array","nullable":true,"items":{"type":"integer","format":"int64","minimum":0}}`},
+		{"keys", labels, "max=3,dive,keys,min=2,endkeys,required",
+			`{"type":"object","additionalProperties":{"type":"string"},"maxProperties":3}`},
+		{"patterns", stringT, "startswith=P-,endswith=.v1,email",
+			`{"type":"string","format":"email","pattern":"^P-","allOf":[{"pattern":"\\.v1$"}]}`},
+		{"alternatives", stringT, "email|url,min=3",
+			`{"type":"string","minLength":3}`},
+		{"unknown rules", stringT, "required,excluded_with=Other",
+			`{"type":"string"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := descField{name: "Field", t: tt.t, tags: "validate:\"" + tt.tag + "\""}
+			ref, err := genFieldSchema(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := genValidate(ref, f); err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(ref.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(t, got, tt.want) {
+				t.Errorf("got schema %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenValidateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *typeDesc
+		tag  string
+	}{
+		{"bad number", &typeDesc{typeName: "int", isScalar: true}, "min=one"},
+		{"bad length", &typeDesc{typeName: "string", isScalar: true}, "max=-1"},
+		{"empty length", &typeDesc{typeName: "string", isScalar: true}, "lt=0"},
+		{"bad oneof", &typeDesc{typeName: "int", isScalar: true}, "oneof=1 two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := descField{name: "Field", t: tt.t, tags: "validate:\"" + tt.tag + "\""}
+			ref, err := genFieldSchema(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := genValidate(ref, f); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
+
+func TestTypedDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *typeDesc
+		def  string
+		want interface{}
+	}{
+		{"string", &typeDesc{typeName: "string", isScalar: true}, "20", "20"},
+		{"int", &typeDesc{typeName: "int", isScalar: true}, "20", int64(20)},
+		{"uint", &typeDesc{typeName: "uint32", isScalar: true}, "20", uint64(20)},
+		{"float", &typeDesc{typeName: "float64", isScalar: true}, "0.5", 0.5},
+		{"bool", &typeDesc{typeName: "bool", isScalar: true}, "true", true},
+		{"pointer", &typeDesc{isPtr: &typeDesc{typeName: "int", isScalar: true}}, "-1", int64(-1)},
+		{"slice", &typeDesc{isSlice: &descSlice{t: &typeDesc{typeName: "int", isScalar: true}}}, "3", []interface{}{int64(3)}},
+		{"time", &typeDesc{isSpecial: specialTypeTime}, "2020-01-02T03:04:05Z", "2020-01-02T03:04:05Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := typedDefault(tt.t, tt.def)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		t   *typeDesc
+		def string
+	}{
+		{&typeDesc{typeName: "int", isScalar: true}, "twenty"},
+		{&typeDesc{typeName: "uint", isScalar: true}, "-1"},
+		{&typeDesc{typeName: "bool", isScalar: true}, "yes"},
+		{&typeDesc{isSpecial: specialTypeTime}, "yesterday"},
+	} {
+		if _, err := typedDefault(tt.t, tt.def); err == nil {
+			t.Errorf("got no error for default '%v' of %v", tt.def, tt.t.typeName)
+		}
+	}
+}
+
+// jsonEqual compares the JSON documents.
+func jsonEqual(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(g, w)
+}
@@ -4,8 +4,10 @@ go 1.22
 
 require (
 	github.com/getkin/kin-openapi v0.80.0
+	github.com/ghodss/yaml v1.0.0
 	github.com/iancoleman/strcase v0.2.0
 	github.com/pkg/errors v0.9.1
+	golang.org/x/net v0.21.0
 )
 
 require (
@@ -14,7 +16,6 @@ require (
 )
 
 require (
-	github.com/ghodss/yaml v1.0.0 // indirect
 	github.com/go-openapi/jsonpointer v0.19.5 // indirect
 	github.com/go-openapi/swag v0.19.5 // indirect
 	github.com/mailru/easyjson v0.0.0-20190626092158-b2ccc519800e // indirect
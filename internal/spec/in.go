@@ -0,0 +1,55 @@
+// Package spec holds conventions shared by pontoongen and the sdesc runtime,
+// so the generated specs and the runtime behaviour can't drift apart.
+package spec
+
+import "strings"
+
+// Locations of the request values.
+const (
+	LocBody   = "body"
+	LocQuery  = "query"
+	LocHeader = "header"
+	LocForm   = "form"
+	LocPath   = "path"
+)
+
+// In is a parsed `in` struct tag, i.e.
+// `in:"query=foo;required;default=bar"`.
+type In struct {
+	// Location is one of the Loc* constants;
+	// empty if the tag has only directives.
+	Location string
+	// Name is a parameter name (or body format for LocBody).
+	Name string
+
+	Required bool
+	Default  string
+}
+
+// ParseIn parses a value of the `in` struct tag.
+// It returns nil if the tag is empty or "-".
+func ParseIn(tag string) *In {
+	if len(tag) == 0 || tag == "-" {
+		return nil
+	}
+
+	ret := &In{}
+	for _, s := range strings.Split(tag, ";") {
+		if s == "required" {
+			ret.Required = true
+			continue
+		}
+		directive, value, ok := strings.Cut(s, "=")
+		if !ok {
+			continue
+		}
+		switch directive {
+		case LocBody, LocForm, LocHeader, LocQuery, LocPath:
+			ret.Location = directive
+			ret.Name = strings.Split(value, ",")[0]
+		case "default":
+			ret.Default = value
+		}
+	}
+	return ret
+}
@@ -0,0 +1,17 @@
+package spec
+
+import "strings"
+
+// JSONName returns a JSON field name of the struct field
+// given its Go name and the value of its `json` tag.
+// It returns "-" for the fields skipped by encoding/json.
+func JSONName(goName, tag string) string {
+	if tag == "-" {
+		return tag
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name != "" {
+		return name
+	}
+	return goName
+}
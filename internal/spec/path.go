@@ -0,0 +1,23 @@
+package spec
+
+import "strings"
+
+// OperationID returns a default operation ID of the endpoint,
+// i.e. "v1_items__id___get" for GET /v1/items/{id}.
+func OperationID(method, path string) string {
+	ret := strings.NewReplacer("/", "_", "{", "_", "}", "_").Replace(path)
+	ret = strings.TrimPrefix(ret, "_")
+	return strings.ToLower(ret + "_" + method)
+}
+
+// JoinPath joins a route group's prefix and a path pattern,
+// i.e. "/v1/items" and "/{id}" become "/v1/items/{id}".
+func JoinPath(prefix, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+	if pattern == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(pattern, "/")
+}
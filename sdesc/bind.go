@@ -0,0 +1,505 @@
+package sdesc
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+// maxMemory is passed to http.Request.ParseMultipartForm.
+const maxMemory = 32 << 20
+
+var (
+	typeTime            = reflect.TypeOf(time.Time{})
+	typeMultipartFile   = reflect.TypeOf((*multipart.File)(nil)).Elem()
+	typeMultipartHeader = reflect.TypeOf((*multipart.FileHeader)(nil))
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// binder fills input structs from the requests according
+// to their `in` tags.
+// The tags follow the same rules that pontoongen uses
+// to describe request parameters:
+//   - if a struct has no `in` locations at all, the whole body is JSON;
+//   - embedded structs are bound recursively;
+//   - fields without a location are skipped;
+//   - missing values get their `default=`, or are reported
+//     if they are `required`.
+type binder struct {
+	fields  []fieldBinder
+	hasForm bool
+}
+
+type fieldBinder struct {
+	// index is a path to the field, see reflect.Value.FieldByIndex.
+	index  []int
+	goName string
+	in     spec.In
+
+	// json lists the fields of a JSON body that have
+	// `in` directives but no location.
+	json []jsonDirective
+}
+
+type jsonDirective struct {
+	index  []int
+	goName string
+	name   string
+	in     spec.In
+}
+
+// newBinder prepares a binder for a struct type.
+func newBinder(t reflect.Type) (*binder, error) {
+	ret := &binder{}
+	err := ret.addStruct(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (b *binder) addStruct(t reflect.Type, index []int) error {
+	if !hasInLocations(t) {
+		// whole struct is a JSON body
+		dirs, err := jsonDirectives(t, nil)
+		if err != nil {
+			return err
+		}
+		b.fields = append(b.fields, fieldBinder{
+			index:  index,
+			goName: t.Name(),
+			in:     spec.In{Location: spec.LocBody, Name: "json"},
+			json:   dirs,
+		})
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fidx := append(append([]int{}, index...), i)
+
+		if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				continue
+			}
+			err := b.addStruct(ft, fidx)
+			if err != nil {
+				return errors.Wrapf(err, "embedded field '%v'", f.Name)
+			}
+			continue
+		}
+
+		in := spec.ParseIn(f.Tag.Get("in"))
+		if in == nil || in.Location == "" {
+			continue
+		}
+		if !f.IsExported() {
+			return errors.Errorf("field '%v' has an `in` tag but is not exported", f.Name)
+		}
+
+		err := checkFieldType(f.Type, in)
+		if err != nil {
+			return errors.Wrapf(err, "field '%v'", f.Name)
+		}
+		if in.Location == spec.LocForm {
+			b.hasForm = true
+		}
+
+		fb := fieldBinder{
+			index:  fidx,
+			goName: f.Name,
+			in:     *in,
+		}
+		if in.Location == spec.LocBody {
+			bt := f.Type
+			if bt.Kind() == reflect.Ptr {
+				bt = bt.Elem()
+			}
+			if bt.Kind() == reflect.Struct {
+				fb.json, err = jsonDirectives(bt, nil)
+				if err != nil {
+					return errors.Wrapf(err, "field '%v'", f.Name)
+				}
+			}
+		}
+		b.fields = append(b.fields, fb)
+	}
+	return nil
+}
+
+// hasInLocations is true if any of the struct's fields,
+// including embedded ones, has a location in its `in` tag.
+func hasInLocations(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		in := spec.ParseIn(t.Field(i).Tag.Get("in"))
+		if in != nil && in.Location != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonDirectives collects JSON fields with `required` or `default=`
+// directives, descending into embedded structs.
+func jsonDirectives(t reflect.Type, index []int) ([]jsonDirective, error) {
+	var ret []jsonDirective
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fidx := append(append([]int{}, index...), i)
+		jsonTag := f.Tag.Get("json")
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && jsonTag == "" {
+			sub, err := jsonDirectives(f.Type, fidx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "embedded field '%v'", f.Name)
+			}
+			ret = append(ret, sub...)
+			continue
+		}
+
+		in := spec.ParseIn(f.Tag.Get("in"))
+		if in == nil || in.Location != "" || (!in.Required && in.Default == "") {
+			continue
+		}
+		name := spec.JSONName(f.Name, jsonTag)
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if in.Default != "" {
+			err := checkDefault(f.Type, in.Default)
+			if err != nil {
+				return nil, errors.Wrapf(err, "field '%v'", f.Name)
+			}
+		}
+		ret = append(ret, jsonDirective{
+			index:  fidx,
+			goName: f.Name,
+			name:   name,
+			in:     *in,
+		})
+	}
+	return ret, nil
+}
+
+func checkFieldType(t reflect.Type, in *spec.In) error {
+	switch in.Location {
+	case spec.LocBody:
+		if in.Name != "json" {
+			return errors.Errorf("unsupported body format '%v'", in.Name)
+		}
+		return nil
+	case spec.LocForm:
+		if t == typeMultipartFile || t == typeMultipartHeader {
+			return nil
+		}
+	}
+
+	st := t
+	if st.Kind() == reflect.Slice && st.Elem().Kind() != reflect.Uint8 {
+		st = st.Elem()
+	}
+	if !isScalar(st) {
+		return errors.Errorf("cannot read type '%v' from %v", st, in.Location)
+	}
+	if in.Default != "" {
+		return checkDefault(t, in.Default)
+	}
+	return nil
+}
+
+// checkDefault makes sure that the default value can be parsed.
+func checkDefault(t reflect.Type, def string) error {
+	err := setValues(reflect.New(t).Elem(), []string{def})
+	if err != nil {
+		return errors.Wrapf(err, "bad default value '%v'", def)
+	}
+	return nil
+}
+
+// bind fills the struct pointed to by dst;
+// the body is decoded by the codec of its Content-Type.
+// Every missing or malformed value is reported in a single ValidationError.
+func (b *binder) bind(r *http.Request, dst reflect.Value, cs []Codec) error {
+	if b.hasForm {
+		err := r.ParseMultipartForm(maxMemory)
+		if err == http.ErrNotMultipart {
+			err = r.ParseForm()
+		}
+		if err != nil {
+			return errors.Wrap(err, "parsing form")
+		}
+	}
+
+	verr := &ValidationError{}
+	for _, f := range b.fields {
+		fv := fieldByIndex(dst.Elem(), f.index)
+
+		err := f.bind(r, fv, cs, verr)
+		if err != nil {
+			return err
+		}
+	}
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func (f fieldBinder) bind(r *http.Request, fv reflect.Value, cs []Codec, verr *ValidationError) error {
+	var vals []string
+
+	switch f.in.Location {
+	case spec.LocBody:
+		return f.bindBody(r, fv, cs, verr)
+	case spec.LocQuery:
+		vals = r.URL.Query()[f.in.Name]
+	case spec.LocHeader:
+		vals = r.Header.Values(f.in.Name)
+	case spec.LocPath:
+		if v := r.PathValue(f.in.Name); v != "" {
+			vals = []string{v}
+		}
+	case spec.LocForm:
+		if fv.Type() == typeMultipartFile || fv.Type() == typeMultipartHeader {
+			found, err := bindFile(r, f.in.Name, fv)
+			if err != nil {
+				return errors.Wrapf(err, "reading form file '%v'", f.in.Name)
+			}
+			if !found && f.in.Required {
+				verr.add(f.fieldError("value is required"))
+			}
+			return nil
+		}
+		vals = r.PostForm[f.in.Name]
+	}
+
+	if len(vals) == 0 {
+		switch {
+		case f.in.Default != "":
+			vals = []string{f.in.Default}
+		case f.in.Required:
+			verr.add(f.fieldError("value is required"))
+			return nil
+		default:
+			return nil
+		}
+	}
+	err := setValues(fv, vals)
+	if err != nil {
+		verr.add(f.fieldError(err.Error()))
+	}
+	return nil
+}
+
+// bindBody decodes the body; the JSON directives
+// are applied to JSON bodies only.
+func (f fieldBinder) bindBody(r *http.Request, fv reflect.Value, cs []Codec, verr *ValidationError) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading body")
+	}
+
+	var keys map[string]json.RawMessage
+	if len(bytes.TrimSpace(body)) == 0 {
+		if f.in.Required {
+			verr.add(f.fieldError("body is required"))
+			return nil
+		}
+		if fv.Kind() == reflect.Ptr {
+			return nil
+		}
+	} else {
+		c, err := requestCodec(r, cs)
+		if err != nil {
+			return err
+		}
+		err = c.Unmarshal(body, fv.Addr().Interface())
+		if err != nil {
+			return errors.Wrapf(err, "decoding %v body", c.MediaType())
+		}
+		if mediaTypeKey(c.MediaType()) != MediaTypeJSON {
+			return nil
+		}
+		if len(f.json) > 0 {
+			// bodies other than objects have no keys
+			_ = json.Unmarshal(body, &keys)
+		}
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	for _, d := range f.json {
+		if _, ok := keys[d.name]; ok {
+			continue
+		}
+		switch {
+		case d.in.Default != "":
+			// checked by checkDefault
+			_ = setValues(fieldByIndex(fv, d.index), []string{d.in.Default})
+		case d.in.Required:
+			verr.add(FieldError{
+				Field:  d.goName,
+				In:     spec.LocBody,
+				Name:   d.name,
+				Reason: "value is required",
+			})
+		}
+	}
+	return nil
+}
+
+// bindFile sets a multipart.File or a *multipart.FileHeader
+// from the form; found is false if there's no such file.
+func bindFile(r *http.Request, name string, fv reflect.Value) (found bool, err error) {
+	file, hdr, err := r.FormFile(name)
+	if err == http.ErrMissingFile || err == http.ErrNotMultipart {
+		// url-encoded forms have no files
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if fv.Type() == typeMultipartHeader {
+		file.Close()
+		fv.Set(reflect.ValueOf(hdr))
+		return true, nil
+	}
+	fv.Set(reflect.ValueOf(file))
+	return true, nil
+}
+
+func (f fieldBinder) fieldError(reason string) FieldError {
+	return FieldError{
+		Field:  f.goName,
+		In:     f.in.Location,
+		Name:   f.in.Name,
+		Reason: reason,
+	}
+}
+
+// setValues converts string values to the type of v.
+// Slices receive every value, other types receive the first one.
+func setValues(v reflect.Value, vals []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		sl := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, s := range vals {
+			err := setScalar(sl.Index(i), s)
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(sl)
+		return nil
+	}
+	return setScalar(v, vals[0])
+}
+
+func isScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == typeTime || reflect.PointerTo(t).Implements(typeTextUnmarshaler) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// setScalar parses s into v; v's type should pass isScalar.
+func setScalar(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		pv := reflect.New(v.Type().Elem())
+		err := setScalar(pv.Elem(), s)
+		if err != nil {
+			return err
+		}
+		v.Set(pv)
+		return nil
+	}
+
+	if v.Type() == typeTime {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.SetBytes([]byte(s))
+	default:
+		return errors.Errorf("cannot convert a string to '%v'", v.Type())
+	}
+	return nil
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that allocates
+// nil embedded pointers on its way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
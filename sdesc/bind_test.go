@@ -0,0 +1,228 @@
+package sdesc
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type testPage struct {
+	PageToken string `in:"query=page_token"`
+}
+
+type testBindRequest struct {
+	testPage
+
+	ID      int64     `in:"path=id"`
+	Tags    []string  `in:"query=tag"`
+	Limit   int       `in:"query=limit;default=20"`
+	Active  *bool     `in:"query=active"`
+	Since   time.Time `in:"query=since"`
+	TraceID string    `in:"header=X-Trace-Id"`
+	Local   string    `in:"query=local;required"`
+
+	Body *testJSONRequest `in:"body=json"`
+}
+
+type testFormRequest struct {
+	Title  string                `in:"form=title"`
+	File   multipart.File        `in:"form=file;required"`
+	Header *multipart.FileHeader `in:"form=file"`
+}
+
+type testJSONDirectives struct {
+	WithDefault  string `json:"with_default" in:"required;default=1234"`
+	RequiredOnly string `in:"required"`
+}
+
+func bindRequest(t *testing.T, in interface{}, r *http.Request) (interface{}, error) {
+	t.Helper()
+	typ := reflect.TypeOf(in)
+	b, err := newBinder(typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := reflect.New(typ)
+	err = b.bind(r, dst, defaultCodecList())
+	return dst.Elem().Interface(), err
+}
+
+func defaultCodecList() []Codec {
+	return []Codec{defaultCodecs()[MediaTypeJSON]}
+}
+
+func TestBindParameters(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost,
+		"/items/42?page_token=next&tag=a&tag=b&active=true&since=2021-01-02T03:04:05Z&local=here",
+		strings.NewReader(`{"name":"posted"}`))
+	r.SetPathValue("id", "42")
+	r.Header.Set("X-Trace-Id", "trace")
+
+	got, err := bindRequest(t, testBindRequest{}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := true
+	want := testBindRequest{
+		testPage: testPage{PageToken: "next"},
+		ID:       42,
+		Tags:     []string{"a", "b"},
+		Limit:    20,
+		Active:   &active,
+		Since:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		TraceID:  "trace",
+		Local:    "here",
+		Body:     &testJSONRequest{Name: "posted"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestBindValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   []FieldError
+	}{
+		{
+			name:   "missing required",
+			target: "/items/42",
+			want: []FieldError{
+				{Field: "Local", In: "query", Name: "local", Reason: "value is required"},
+			},
+		},
+		{
+			name:   "malformed values",
+			target: "/items/42?limit=many&active=maybe&local=here",
+			want: []FieldError{
+				{Field: "Limit", In: "query", Name: "limit"},
+				{Field: "Active", In: "query", Name: "active"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			r.SetPathValue("id", "42")
+
+			_, err := bindRequest(t, testBindRequest{}, r)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("got error %v, want a ValidationError", err)
+			}
+			if len(verr.Fields) != len(tt.want) {
+				t.Fatalf("got fields %+v, want %+v", verr.Fields, tt.want)
+			}
+			for i, f := range verr.Fields {
+				w := tt.want[i]
+				if f.Field != w.Field || f.In != w.In || f.Name != w.Name ||
+					(w.Reason != "" && f.Reason != w.Reason) {
+					t.Errorf("got field %+v, want %+v", f, w)
+				}
+			}
+		})
+	}
+}
+
+func TestBindJSONDirectives(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"RequiredOnly":"set"}`))
+	got, err := bindRequest(t, testJSONDirectives{}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testJSONDirectives{WithDefault: "1234", RequiredOnly: "set"}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	_, err = bindRequest(t, testJSONDirectives{}, r)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Name != "RequiredOnly" {
+		t.Errorf("got error %v, want RequiredOnly to be required", err)
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("title", "report"); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := mw.CreateFormFile("file", "report.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(fw, "contents"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &buf)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	v, err := bindRequest(t, testFormRequest{}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := v.(testFormRequest)
+	if got.Title != "report" {
+		t.Errorf("got title %q, want report", got.Title)
+	}
+	if got.Header == nil || got.Header.Filename != "report.txt" {
+		t.Errorf("got file header %+v, want report.txt", got.Header)
+	}
+	if got.File == nil {
+		t.Fatal("file is not bound")
+	}
+	defer got.File.Close()
+	body, err := io.ReadAll(got.File)
+	if err != nil || string(body) != "contents" {
+		t.Errorf("got file %q (%v), want contents", body, err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("title=report"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err = bindRequest(t, testFormRequest{}, r)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Name != "file" {
+		t.Errorf("got error %v, want the file to be required", err)
+	}
+}
+
+func TestNewBinderRejectsBadTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+	}{
+		{"unexported field", struct {
+			id string `in:"query=id"`
+		}{}},
+		{"struct in query", struct {
+			Page testPage `in:"query=page"`
+		}{}},
+		{"bad default", struct {
+			Limit int `in:"query=limit;default=many"`
+		}{}},
+		{"unknown body format", struct {
+			Body testJSONRequest `in:"body=xml"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newBinder(reflect.TypeOf(tt.in)); err == nil {
+				t.Error("newBinder didn't fail")
+			}
+		})
+	}
+}
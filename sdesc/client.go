@@ -0,0 +1,458 @@
+package sdesc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Client calls a Service's endpoints over HTTP;
+// it's used by the clients generated by pontoongen with -client.
+type Client struct {
+	base    *url.URL
+	http    *http.Client
+	editors []func(*http.Request) error
+}
+
+// ClientOption configures the Client.
+type ClientOption func(*Client)
+
+// WithHTTPClient makes the Client send the requests via c
+// instead of http.DefaultClient.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(cl *Client) {
+		cl.http = c
+	}
+}
+
+// WithRequestEditor makes the Client call fn for every request
+// before it's sent, i.e. to set the credentials.
+func WithRequestEditor(fn func(*http.Request) error) ClientOption {
+	return func(cl *Client) {
+		cl.editors = append(cl.editors, fn)
+	}
+}
+
+// NewClient creates a Client of the Service at baseURL,
+// i.e. "https://api.example.com".
+func NewClient(baseURL string, opts ...ClientOption) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing base URL")
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.Errorf("base URL '%v' should be absolute", baseURL)
+	}
+	ret := &Client{base: u, http: http.DefaultClient}
+	for _, o := range opts {
+		o(ret)
+	}
+	return ret, nil
+}
+
+// ClientError is a failed call's response.
+// Its StatusCode is the response's one; the reported invalid
+// request values are available via errors.As as a *ValidationError.
+type ClientError struct {
+	Code int
+	// Message is the error reported by the Service.
+	Message string
+	// Fields lists invalid request values, if any.
+	Fields []FieldError
+	// Problem is set if the Service reports RFC 7807 problems.
+	Problem *ProblemDetails
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *ClientError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %v", e.Code, http.StatusText(e.Code))
+	}
+	return fmt.Sprintf("%d %v: %v", e.Code, http.StatusText(e.Code), e.Message)
+}
+
+func (e *ClientError) StatusCode() int {
+	return e.Code
+}
+
+func (e *ClientError) Unwrap() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: e.Fields}
+}
+
+// DecodeBody decodes the response body into v, i.e. into
+// a custom error body documented by a response directive.
+func (e *ClientError) DecodeBody(v interface{}) error {
+	return json.Unmarshal(e.Body, v)
+}
+
+func newClientError(rsp *http.Response) *ClientError {
+	body, _ := io.ReadAll(rsp.Body)
+	ret := &ClientError{Code: rsp.StatusCode, Body: body}
+
+	mt, _, _ := mime.ParseMediaType(rsp.Header.Get("Content-Type"))
+	switch mt {
+	case ContentTypeProblem:
+		var p ProblemDetails
+		if json.Unmarshal(body, &p) == nil {
+			ret.Problem = &p
+			ret.Message = p.Detail
+			ret.Fields = p.InvalidParams
+		}
+	case MediaTypeJSON:
+		var e errorResponse
+		if json.Unmarshal(body, &e) == nil {
+			ret.Message = e.Error
+			ret.Fields = e.Fields
+		}
+	}
+	if ret.Message == "" && ret.Problem == nil {
+		ret.Message = strings.TrimSpace(string(body))
+	}
+	return ret
+}
+
+// ClientRequest is a request being built by the generated client.
+type ClientRequest struct {
+	method  string
+	pattern string
+
+	path   map[string]string
+	query  url.Values
+	header http.Header
+	form   url.Values
+	files  []clientFile
+	// multipart is true if the form is sent as multipart/form-data.
+	multipart bool
+
+	body    interface{}
+	hasBody bool
+
+	// err is the first error of MarshalText.
+	err error
+}
+
+type clientFile struct {
+	name     string
+	filename string
+	r        io.Reader
+	header   *multipart.FileHeader
+}
+
+// NewRequest starts a request of the route's method and path pattern,
+// i.e. "/v1/items/{id}".
+func (c *Client) NewRequest(method, pattern string) *ClientRequest {
+	return &ClientRequest{
+		method:  method,
+		pattern: pattern,
+		path:    map[string]string{},
+		query:   url.Values{},
+		header:  http.Header{},
+		form:    url.Values{},
+	}
+}
+
+// Path sets the path parameter.
+func (r *ClientRequest) Path(name, v string) {
+	r.path[name] = v
+}
+
+// Query adds the query parameter's values.
+func (r *ClientRequest) Query(name string, vs ...string) {
+	r.query[name] = append(r.query[name], vs...)
+}
+
+// Header adds the header's values.
+func (r *ClientRequest) Header(name string, vs ...string) {
+	for _, v := range vs {
+		r.header.Add(name, v)
+	}
+}
+
+// Form adds the form field's values.
+func (r *ClientRequest) Form(name string, vs ...string) {
+	r.form[name] = append(r.form[name], vs...)
+}
+
+// Multipart makes the form multipart/form-data even without files.
+func (r *ClientRequest) Multipart() {
+	r.multipart = true
+}
+
+// FormFile adds the file to the multipart form.
+func (r *ClientRequest) FormFile(name, filename string, f io.Reader) {
+	r.files = append(r.files, clientFile{name: name, filename: filename, r: f})
+}
+
+// FormFileHeader adds the uploaded file to the multipart form.
+func (r *ClientRequest) FormFileHeader(name string, fh *multipart.FileHeader) {
+	r.files = append(r.files, clientFile{name: name, filename: fh.Filename, header: fh})
+}
+
+// Body sets the JSON body.
+func (r *ClientRequest) Body(v interface{}) {
+	r.body = v
+	r.hasBody = true
+}
+
+// MarshalText formats the value of a parameter;
+// its error is returned by the request's call.
+func (r *ClientRequest) MarshalText(v encoding.TextMarshaler) string {
+	b, err := v.MarshalText()
+	if err != nil && r.err == nil {
+		r.err = errors.Wrap(err, "formatting parameter")
+	}
+	return string(b)
+}
+
+// Do sends the request and decodes the JSON response into out,
+// unless out is nil. Failed calls return a *ClientError.
+func (c *Client) Do(ctx context.Context, req *ClientRequest, out interface{}) error {
+	rsp, err := c.DoRaw(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, rsp.Body)
+		return nil
+	}
+	err = json.NewDecoder(rsp.Body).Decode(out)
+	if err != nil {
+		return errors.Wrap(err, "decoding response")
+	}
+	return nil
+}
+
+// DoRaw sends the request and returns the successful response;
+// the caller should close its body. Failed calls return a *ClientError.
+func (c *Client) DoRaw(ctx context.Context, req *ClientRequest) (*http.Response, error) {
+	hr, err := c.httpRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if hr.Header.Get("Accept") == "" {
+		hr.Header.Set("Accept", MediaTypeJSON)
+	}
+	for _, e := range c.editors {
+		err = e(hr)
+		if err != nil {
+			return nil, errors.Wrap(err, "editing request")
+		}
+	}
+
+	rsp, err := c.http.Do(hr)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode >= http.StatusBadRequest {
+		defer rsp.Body.Close()
+		return nil, newClientError(rsp)
+	}
+	return rsp, nil
+}
+
+// Stream is a stream of the elements of a streaming route.
+type Stream[T any] struct {
+	ch     chan T
+	err    error
+	cancel context.CancelFunc
+}
+
+// C returns the channel of the elements; it's closed once the stream
+// ends, fails, the context is done or the Stream is closed.
+func (s *Stream[T]) C() <-chan T {
+	return s.ch
+}
+
+// Err returns the error that ended the stream once C is closed:
+// a malformed element, a dropped connection or the context's error.
+// It's nil if the whole stream was received.
+func (s *Stream[T]) Err() error {
+	return s.err
+}
+
+// Close stops receiving the stream and closes its connection.
+func (s *Stream[T]) Close() {
+	s.cancel()
+}
+
+// DoStream sends the request of a streaming route and returns
+// the Stream of its elements. Failed calls return a *ClientError.
+func DoStream[T any](ctx context.Context, c *Client, req *ClientRequest) (*Stream[T], error) {
+	ctx, cancel := context.WithCancel(ctx)
+	req.Header("Accept", MediaTypeNDJSON)
+	rsp, err := c.DoRaw(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	ret := &Stream[T]{ch: make(chan T), cancel: cancel}
+	go func() {
+		defer close(ret.ch)
+		defer rsp.Body.Close()
+		ret.err = receiveStream(ctx, rsp.Body, ret.ch)
+		if ret.err != nil && ctx.Err() != nil {
+			ret.err = ctx.Err()
+		}
+	}()
+	return ret, nil
+}
+
+// receiveStream sends the NDJSON elements of the body to the channel.
+func receiveStream[T any](ctx context.Context, body io.Reader, ch chan<- T) error {
+	sc := bufio.NewScanner(body)
+	sc.Buffer(nil, maxMemory)
+	n := 0
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(line, &v); err != nil {
+			return errors.Wrapf(err, "decoding stream element %v", n)
+		}
+		n++
+		select {
+		case ch <- v:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Wrap(sc.Err(), "reading stream")
+}
+
+func (c *Client) httpRequest(ctx context.Context, req *ClientRequest) (*http.Request, error) {
+	if req.err != nil {
+		return nil, req.err
+	}
+	p, err := expandPattern(req.pattern, req.path)
+	if err != nil {
+		return nil, err
+	}
+	u := *c.base
+	u.RawPath = strings.TrimSuffix(c.base.EscapedPath(), "/") + p
+	u.Path, err = url.PathUnescape(u.RawPath)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	contentType := ""
+	switch {
+	case len(req.files) > 0 || req.multipart:
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		for name, vs := range req.form {
+			for _, v := range vs {
+				if err := mw.WriteField(name, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, f := range req.files {
+			fw, err := mw.CreateFormFile(f.name, f.filename)
+			if err != nil {
+				return nil, err
+			}
+			err = f.copy(fw)
+			if err != nil {
+				return nil, errors.Wrapf(err, "reading form file '%v'", f.name)
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+		body, contentType = &buf, mw.FormDataContentType()
+	case len(req.form) > 0:
+		body, contentType = strings.NewReader(req.form.Encode()), mediaTypeForm
+	case req.hasBody:
+		b, err := JSONCodec{}.Marshal(req.body)
+		if err != nil {
+			return nil, errors.Wrap(err, "encoding body")
+		}
+		body, contentType = bytes.NewReader(b), MediaTypeJSON
+	}
+
+	hr, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range req.header {
+		hr.Header[k] = vs
+	}
+	if contentType != "" {
+		hr.Header.Set("Content-Type", contentType)
+	}
+	return hr, nil
+}
+
+func (f clientFile) copy(w io.Writer) error {
+	if f.header == nil {
+		_, err := io.Copy(w, f.r)
+		return err
+	}
+	r, err := f.header.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// expandPattern substitutes the path parameters of the http.ServeMux pattern.
+func expandPattern(pattern string, params map[string]string) (string, error) {
+	var ret strings.Builder
+	for {
+		i := strings.IndexByte(pattern, '{')
+		if i < 0 {
+			ret.WriteString(pattern)
+			return ret.String(), nil
+		}
+		j := strings.IndexByte(pattern[i:], '}')
+		if j < 0 {
+			return "", errors.Errorf("bad path pattern '%v'", pattern)
+		}
+		ret.WriteString(pattern[:i])
+		name := pattern[i+1 : i+j]
+		pattern = pattern[i+j+1:]
+
+		if name == "$" {
+			continue
+		}
+		rest := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		v, ok := params[name]
+		if !ok || (v == "" && !rest) {
+			return "", errors.Errorf("path parameter '%v' is not set", name)
+		}
+		if rest {
+			segs := strings.Split(v, "/")
+			for k := range segs {
+				segs[k] = url.PathEscape(segs[k])
+			}
+			ret.WriteString(strings.Join(segs, "/"))
+			continue
+		}
+		ret.WriteString(url.PathEscape(v))
+	}
+}
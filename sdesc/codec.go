@@ -0,0 +1,345 @@
+package sdesc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Media types of the built-in codecs and the ones
+// commonly provided by the users, see WithCodecs.
+const (
+	MediaTypeJSON     = "application/json"
+	MediaTypeXML      = "application/xml"
+	MediaTypeMsgPack  = "application/msgpack"
+	MediaTypeProtobuf = "application/x-protobuf"
+)
+
+// Codec encodes and decodes bodies of a single media type.
+//
+// JSON and XML codecs are built in; others, i.e. MessagePack or protobuf,
+// are added to the Router by WithCodecs.
+type Codec interface {
+	// MediaType returns the media type of the bodies, i.e. "application/json".
+	MediaType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes bodies as application/json.
+type JSONCodec struct{}
+
+func (JSONCodec) MediaType() string {
+	return MediaTypeJSON
+}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// XMLCodec encodes bodies as application/xml using encoding/xml.
+// Slices and maps, having no element of their own, are wrapped
+// in the <items> root element; every map value is an <item>
+// with the key attribute:
+//
+//	<items><item key="a">1</item></items>
+type XMLCodec struct{}
+
+// xmlRoot is the root element of the wrapped slices and maps.
+var xmlRoot = xml.StartElement{Name: xml.Name{Local: "items"}}
+
+func (XMLCodec) MediaType() string {
+	return MediaTypeXML
+}
+
+func (XMLCodec) Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || !xmlWrapped(rv.Type()) {
+		return xml.Marshal(v)
+	}
+
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	err := enc.EncodeToken(xmlRoot)
+	if err != nil {
+		return nil, err
+	}
+	if rv.Kind() == reflect.Map {
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			item := xml.StartElement{
+				Name: xml.Name{Local: "item"},
+				Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: fmt.Sprint(k)}},
+			}
+			if err := enc.EncodeElement(rv.MapIndex(k).Interface(), item); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for i := 0; i < rv.Len(); i++ {
+			if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	err = enc.EncodeToken(xmlRoot.End())
+	if err == nil {
+		err = enc.Flush()
+	}
+	return buf.Bytes(), err
+}
+
+func (XMLCodec) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || !xmlWrapped(rv.Elem().Type()) {
+		return xml.Unmarshal(data, v)
+	}
+	rv = rv.Elem()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				// the root
+				if rv.Kind() == reflect.Map && rv.IsNil() {
+					rv.Set(reflect.MakeMap(rv.Type()))
+				}
+				if rv.Kind() == reflect.Slice {
+					rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+				}
+				continue
+			}
+			err := xmlDecodeItem(dec, t, rv)
+			if err != nil {
+				return err
+			}
+			depth--
+		}
+	}
+}
+
+// xmlDecodeItem decodes the item of the wrapped slice or map.
+func xmlDecodeItem(dec *xml.Decoder, start xml.StartElement, rv reflect.Value) error {
+	item := reflect.New(rv.Type().Elem())
+	err := dec.DecodeElement(item.Interface(), &start)
+	if err != nil {
+		return err
+	}
+	if rv.Kind() == reflect.Slice {
+		rv.Set(reflect.Append(rv, item.Elem()))
+		return nil
+	}
+
+	key := reflect.New(rv.Type().Key())
+	for _, a := range start.Attr {
+		if a.Name.Local == "key" {
+			err = xml.Unmarshal([]byte("<k>"+xmlEscape(a.Value)+"</k>"), key.Interface())
+			break
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "decoding key")
+	}
+	rv.SetMapIndex(key.Elem(), item.Elem())
+	return nil
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// xmlWrapped is true for the slices and maps
+// wrapped in the root element; []byte are text.
+func xmlWrapped(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// WithCodecs registers codecs for the media types of the Services,
+// replacing the built-in ones of the same media type.
+func WithCodecs(cs ...Codec) RouterOption {
+	return func(r *Router) {
+		for _, c := range cs {
+			r.codecs[mediaTypeKey(c.MediaType())] = c
+		}
+	}
+}
+
+// defaultCodecs returns the built-in codecs.
+func defaultCodecs() map[string]Codec {
+	return map[string]Codec{
+		MediaTypeJSON: JSONCodec{},
+		MediaTypeXML:  XMLCodec{},
+	}
+}
+
+// mediaTypeKey strips the parameters of the media type and lowercases it.
+func mediaTypeKey(mt string) string {
+	if i := strings.IndexByte(mt, ';'); i >= 0 {
+		mt = mt[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// requestCodec returns the codec of the request's Content-Type;
+// requests without a Content-Type use the first codec.
+func requestCodec(r *http.Request, cs []Codec) (Codec, error) {
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return cs[0], nil
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return nil, WithStatus(errors.Wrap(err, "bad Content-Type"), http.StatusUnsupportedMediaType)
+	}
+	for _, c := range cs {
+		if mediaTypeKey(c.MediaType()) == mt {
+			return c, nil
+		}
+	}
+	return nil, WithStatus(errors.Errorf("unsupported Content-Type '%v'", mt), http.StatusUnsupportedMediaType)
+}
+
+// responseCodec chooses the codec by the request's Accept header;
+// the codecs are listed in order of the Service's preference.
+func responseCodec(r *http.Request, cs []Codec) (Codec, error) {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return cs[0], nil
+	}
+
+	ranges := parseAccept(strings.Join(accept, ","))
+	var ret Codec
+	best := 0.0
+	for _, c := range cs {
+		q := acceptQuality(ranges, mediaTypeKey(c.MediaType()))
+		if q > best {
+			ret, best = c, q
+		}
+	}
+	if ret == nil {
+		return nil, WithStatus(errors.Errorf("none of the media types %v is acceptable", mediaTypes(cs)), http.StatusNotAcceptable)
+	}
+	return ret, nil
+}
+
+func mediaTypes(cs []Codec) []string {
+	ret := make([]string, 0, len(cs))
+	for _, c := range cs {
+		ret = append(ret, c.MediaType())
+	}
+	return ret
+}
+
+// acceptRange is a single media range of the Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses the Accept header, most specific ranges first.
+func parseAccept(h string) []acceptRange {
+	ret := []acceptRange{}
+	for _, part := range strings.Split(h, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mt, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		ret = append(ret, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].specificity() > ret[j].specificity()
+	})
+	return ret
+}
+
+func (a acceptRange) specificity() int {
+	switch {
+	case a.typ == "*":
+		return 0
+	case a.subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+// acceptQuality returns the quality of the media type
+// given by its most specific matching range, 0 if it's not acceptable.
+func acceptQuality(ranges []acceptRange, mt string) float64 {
+	typ, subtype, _ := strings.Cut(mt, "/")
+	for _, a := range ranges {
+		if (a.typ == "*" || a.typ == typ) && (a.subtype == "*" || a.subtype == subtype) {
+			return a.q
+		}
+	}
+	return 0
+}
+
+// negotiation holds the codecs of the call being served.
+type negotiation struct {
+	h    *rpcHandler
+	resp Codec
+}
+
+type ctxKeyNegotiation struct{}
+
+func withNegotiation(ctx context.Context, n negotiation) context.Context {
+	return context.WithValue(ctx, ctxKeyNegotiation{}, n)
+}
+
+func negotiationFromContext(ctx context.Context) (negotiation, bool) {
+	ret, ok := ctx.Value(ctxKeyNegotiation{}).(negotiation)
+	return ret, ok
+}
@@ -0,0 +1,179 @@
+package sdesc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testService is a Service registering the routes by fn.
+type testService struct {
+	opts []ServiceOption
+	fn   func(HTTPRouter)
+}
+
+func (s testService) ServiceOptions() []ServiceOption { return s.opts }
+func (s testService) RegisterHTTP(r HTTPRouter)       { s.fn(r) }
+
+type testXMLItem struct {
+	ID   string   `xml:"id"`
+	Tags []string `xml:"tag"`
+}
+
+func TestXMLCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"struct", &testXMLItem{ID: "a", Tags: []string{"x", "y"}},
+			`<testXMLItem><id>a</id><tag>x</tag><tag>y</tag></testXMLItem>`},
+		{"slice", &[]testXMLItem{{ID: "a"}, {ID: "b"}},
+			`<items><testXMLItem><id>a</id></testXMLItem><testXMLItem><id>b</id></testXMLItem></items>`},
+		{"empty slice", &[]testXMLItem{}, `<items></items>`},
+		{"scalars", &[]int{1, 2}, `<items><int>1</int><int>2</int></items>`},
+		{"map", &map[string]testXMLItem{"b": {ID: "2"}, "a&": {ID: "1"}},
+			`<items><item key="a&amp;"><id>1</id></item><item key="b"><id>2</id></item></items>`},
+		{"int keys", &map[int]string{2: "b", 1: "a"},
+			`<items><item key="1">a</item><item key="2">b</item></items>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := XMLCodec{}.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("got %s, want %s", body, tt.want)
+			}
+
+			got := reflect.New(reflect.TypeOf(tt.v).Elem())
+			if err := (XMLCodec{}).Unmarshal(body, got.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Interface(), tt.v) {
+				t.Errorf("got %#v back, want %#v", got.Elem(), reflect.ValueOf(tt.v).Elem())
+			}
+		})
+	}
+}
+
+func TestCodecNegotiation(t *testing.T) {
+	r := NewRouter()
+	r.Register(testService{
+		opts: []ServiceOption{WithMediaTypes(MediaTypeJSON, MediaTypeXML)},
+		fn: func(r HTTPRouter) {
+			r.MethodFunc(http.MethodGet, "/items", func(r *http.Request) ([]testXMLItem, error) {
+				return []testXMLItem{{ID: "a"}}, nil
+			})
+			r.MethodFunc(http.MethodPost, "/items", func(r *http.Request, in testXMLItem) (*testXMLItem, error) {
+				return &in, nil
+			})
+		},
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		accept      string
+		contentType string
+		body        string
+		wantCode    int
+		wantType    string
+		wantBody    string
+	}{
+		{
+			name:     "default",
+			method:   http.MethodGet,
+			wantCode: http.StatusOK,
+			wantType: MediaTypeJSON,
+			wantBody: `[{"ID":"a","Tags":null}]`,
+		},
+		{
+			name:     "XML",
+			method:   http.MethodGet,
+			accept:   "application/json;q=0.5, application/xml",
+			wantCode: http.StatusOK,
+			wantType: MediaTypeXML,
+			wantBody: `<items><testXMLItem><id>a</id></testXMLItem></items>`,
+		},
+		{
+			name:     "any",
+			method:   http.MethodGet,
+			accept:   "*/*",
+			wantCode: http.StatusOK,
+			wantType: MediaTypeJSON,
+		},
+		{
+			name:     "not acceptable",
+			method:   http.MethodGet,
+			accept:   "text/csv",
+			wantCode: http.StatusNotAcceptable,
+		},
+		{
+			name:        "XML body",
+			method:      http.MethodPost,
+			accept:      MediaTypeXML,
+			contentType: "application/xml; charset=utf-8",
+			body:        `<testXMLItem><id>b</id><tag>x</tag></testXMLItem>`,
+			wantCode:    http.StatusOK,
+			wantType:    MediaTypeXML,
+			wantBody:    `<testXMLItem><id>b</id><tag>x</tag></testXMLItem>`,
+		},
+		{
+			name:        "unsupported body",
+			method:      http.MethodPost,
+			contentType: "text/csv",
+			body:        "b",
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "malformed Content-Type",
+			method:      http.MethodPost,
+			contentType: "application/",
+			body:        "b",
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+"/items", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rsp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rsp.Body.Close()
+			body, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rsp.StatusCode != tt.wantCode {
+				t.Errorf("got status %v, want %v; body %s", rsp.StatusCode, tt.wantCode, body)
+			}
+			if tt.wantType != "" && rsp.Header.Get("Content-Type") != tt.wantType {
+				t.Errorf("got Content-Type %q, want %q", rsp.Header.Get("Content-Type"), tt.wantType)
+			}
+			if got := strings.TrimSpace(string(body)); tt.wantBody != "" && got != tt.wantBody {
+				t.Errorf("got body %s, want %s", got, tt.wantBody)
+			}
+			if rsp.StatusCode == http.StatusOK && rsp.Header.Get("Vary") != "Accept" {
+				t.Errorf("got Vary %q, want Accept", rsp.Header.Get("Vary"))
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package sdesc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+// This file holds the API of the code generated by pontoongen;
+// it's not meant to be used directly.
+
+// CompiledRoute is a route with a reflection-free handler
+// generated by pontoongen.
+type CompiledRoute struct {
+	Method  string
+	Pattern string
+
+	OperationID string
+	Tags        []string
+	Deprecated  bool
+	Security    []SecurityRequirement
+	NoSecurity  bool
+	Timeout     time.Duration
+
+	// Output is true if Serve writes the handler's result by WriteResult.
+	Output bool
+	// Stream is true if Serve writes the handler's result by WriteStream.
+	Stream bool
+	// WebSocket is true if Serve calls ServeWebSocket.
+	WebSocket bool
+	Origins   []string
+	// Serve binds the input, calls the handler and writes its result.
+	// Its errors are reported like the errors of RPCHandlers.
+	Serve func(http.ResponseWriter, *http.Request) error
+}
+
+// RegisterCompiled registers the Service's generated routes in the mux,
+// configured by the Service's options and the Router's options.
+// Routes with middlewares of their own or of their groups
+// aren't generated, see HTTPRouter.With.
+func RegisterCompiled(mux *http.ServeMux, svc Service, routes []CompiledRoute, opts ...RouterOption) {
+	r := NewRouter(opts...)
+	r.mux = mux
+
+	cfg := NewHandlerConfig(svc.ServiceOptions()...)
+	for _, cr := range routes {
+		rc := RouteConfig{
+			tags:        cr.Tags,
+			operationID: cr.OperationID,
+			deprecated:  cr.Deprecated,
+			security:    cr.Security,
+			noSecurity:  cr.NoSecurity,
+			timeout:     cr.Timeout,
+			origins:     cr.Origins,
+		}
+		r.mount(cr.Method, cr.Pattern, &rpcHandler{cfg: cfg, hasOut: cr.Output || cr.Stream, stream: cr.Stream, socket: cr.WebSocket, serve: cr.Serve}, cfg, rc)
+	}
+}
+
+// CallContext returns the context for a context-first handler;
+// see RequestFromContext.
+func CallContext(w http.ResponseWriter, r *http.Request) context.Context {
+	return withCall(r.Context(), r, w)
+}
+
+// WriteResult writes the handler's output
+// in the media type negotiated for the request.
+func WriteResult(w http.ResponseWriter, r *http.Request, v interface{}) {
+	n, ok := negotiationFromContext(r.Context())
+	if !ok || n.resp == nil {
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+	n.h.writeResult(w, r, n.resp, v)
+}
+
+// Binding reads request values for the generated code,
+// following the same rules as RPCHandlers' binding.
+type Binding struct {
+	r    *http.Request
+	verr ValidationError
+	err  error
+}
+
+// NewBinding starts binding the request;
+// hasForm is true if the input has form values.
+func NewBinding(r *http.Request, hasForm bool) *Binding {
+	ret := &Binding{r: r}
+	if hasForm {
+		err := r.ParseMultipartForm(maxMemory)
+		if err == http.ErrNotMultipart {
+			err = r.ParseForm()
+		}
+		if err != nil {
+			ret.err = errors.Wrap(err, "parsing form")
+		}
+	}
+	return ret
+}
+
+// Values returns the values of the query, header, path or form parameter.
+// Missing values get the default one, if any;
+// missing required values are reported and ok is false.
+func (b *Binding) Values(field, loc, name string, required bool, def string) (vals []string, ok bool) {
+	switch loc {
+	case spec.LocQuery:
+		vals = b.r.URL.Query()[name]
+	case spec.LocHeader:
+		vals = b.r.Header.Values(name)
+	case spec.LocPath:
+		if v := b.r.PathValue(name); v != "" {
+			vals = []string{v}
+		}
+	case spec.LocForm:
+		vals = b.r.PostForm[name]
+	}
+
+	if len(vals) > 0 {
+		return vals, true
+	}
+	switch {
+	case def != "":
+		return []string{def}, true
+	case required:
+		b.Missing(field, loc, name)
+	}
+	return nil, false
+}
+
+// Value is Values that returns the first value.
+func (b *Binding) Value(field, loc, name string, required bool, def string) (string, bool) {
+	vals, ok := b.Values(field, loc, name, required, def)
+	if !ok {
+		return "", false
+	}
+	return vals[0], true
+}
+
+// FormFile returns the file of the multipart form.
+func (b *Binding) FormFile(field, name string, required bool) (multipart.File, bool) {
+	file, _, ok := b.formFile(field, name, required)
+	return file, ok
+}
+
+// FormFileHeader returns the header of the file of the multipart form.
+func (b *Binding) FormFileHeader(field, name string, required bool) (*multipart.FileHeader, bool) {
+	file, hdr, ok := b.formFile(field, name, required)
+	if ok {
+		file.Close()
+	}
+	return hdr, ok
+}
+
+func (b *Binding) formFile(field, name string, required bool) (multipart.File, *multipart.FileHeader, bool) {
+	if b.err != nil {
+		return nil, nil, false
+	}
+	file, hdr, err := b.r.FormFile(name)
+	if err == http.ErrMissingFile || err == http.ErrNotMultipart {
+		// url-encoded forms have no files
+		if required {
+			b.Missing(field, spec.LocForm, name)
+		}
+		return nil, nil, false
+	}
+	if err != nil {
+		b.err = errors.Wrapf(err, "reading form file '%v'", name)
+		return nil, nil, false
+	}
+	return file, hdr, true
+}
+
+// Body decodes the body into dst by the codec of its Content-Type.
+// It returns the keys of the JSON body object,
+// ok is false if the body's JSON directives should not be applied.
+func (b *Binding) Body(field string, required bool, dst interface{}) (keys map[string]json.RawMessage, ok bool) {
+	if b.err != nil {
+		return nil, false
+	}
+	body, err := io.ReadAll(b.r.Body)
+	if err != nil {
+		b.err = errors.Wrap(err, "reading body")
+		return nil, false
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			b.Fail(field, spec.LocBody, "json", "body is required")
+			return nil, false
+		}
+		return nil, true
+	}
+
+	cs := []Codec{JSONCodec{}}
+	if n, ok := negotiationFromContext(b.r.Context()); ok {
+		cs = n.h.codecs
+	}
+	c, err := requestCodec(b.r, cs)
+	if err != nil {
+		b.err = err
+		return nil, false
+	}
+	err = c.Unmarshal(body, dst)
+	if err != nil {
+		b.err = errors.Wrapf(err, "decoding %v body", c.MediaType())
+		return nil, false
+	}
+	if mediaTypeKey(c.MediaType()) != MediaTypeJSON {
+		return nil, false
+	}
+	// bodies other than objects have no keys
+	_ = json.Unmarshal(body, &keys)
+	return keys, true
+}
+
+// Missing reports a missing required value.
+func (b *Binding) Missing(field, loc, name string) {
+	b.Fail(field, loc, name, "value is required")
+}
+
+// Fail reports an invalid value.
+func (b *Binding) Fail(field, loc, name, reason string) {
+	b.verr.add(FieldError{
+		Field:  field,
+		In:     loc,
+		Name:   name,
+		Reason: reason,
+	})
+}
+
+// Err returns the binding error, if any.
+func (b *Binding) Err() error {
+	if b.err != nil {
+		var sc StatusCoder
+		if errors.As(b.err, &sc) {
+			return b.err
+		}
+		return WithStatus(b.err, http.StatusBadRequest)
+	}
+	if len(b.verr.Fields) > 0 {
+		return &b.verr
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package sdesc
+
+import (
+	"context"
+	"net/http"
+)
+
+// call is the HTTP call behind handler's context.Context.
+type call struct {
+	r *http.Request
+	w http.ResponseWriter
+}
+
+type ctxKeyCall struct{}
+
+func withCall(ctx context.Context, r *http.Request, w http.ResponseWriter) context.Context {
+	return context.WithValue(ctx, ctxKeyCall{}, call{r: r, w: w})
+}
+
+func callFromContext(ctx context.Context) (call, bool) {
+	ret, ok := ctx.Value(ctxKeyCall{}).(call)
+	return ret, ok
+}
+
+// RequestFromContext returns the HTTP request
+// that's served by a context-first handler, i.e.
+// func(context.Context, In) (Out, error).
+func RequestFromContext(ctx context.Context) (*http.Request, bool) {
+	c, ok := callFromContext(ctx)
+	return c.r, ok
+}
+
+// HeaderFromContext returns headers of the request
+// that's served by a context-first handler,
+// nil if there's none.
+func HeaderFromContext(ctx context.Context) http.Header {
+	c, ok := callFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return c.r.Header
+}
+
+// RemoteAddrFromContext returns the network address that sent
+// the request served by a context-first handler,
+// empty if there's none.
+// See http.Request.RemoteAddr.
+func RemoteAddrFromContext(ctx context.Context) string {
+	c, ok := callFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return c.r.RemoteAddr
+}
+
+// ResponseHeaderFromContext returns headers of the response
+// to the request served by a context-first handler, nil if there's none.
+// Set them before returning from the handler.
+func ResponseHeaderFromContext(ctx context.Context) http.Header {
+	c, ok := callFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return c.w.Header()
+}
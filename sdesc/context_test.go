@@ -0,0 +1,71 @@
+package sdesc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextAccessors(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/items/{id}", func(ctx context.Context, in testGetRequest) (*testItem, error) {
+		req, ok := RequestFromContext(ctx)
+		if !ok || req.PathValue("id") != in.ID {
+			t.Errorf("got request %v, %v", req, ok)
+		}
+		if got := HeaderFromContext(ctx).Get("X-Trace-Id"); got != "trace" {
+			t.Errorf("got X-Trace-Id %q", got)
+		}
+		if got := RemoteAddrFromContext(ctx); got != "192.0.2.1:1234" {
+			t.Errorf("got remote address %q", got)
+		}
+		ResponseHeaderFromContext(ctx).Set("X-Item-Id", in.ID)
+		return &testItem{ID: in.ID}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	req.Header.Set("X-Trace-Id", "trace")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %v; body %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("X-Item-Id"); got != "42" {
+		t.Errorf("got X-Item-Id %q, want 42", got)
+	}
+
+	ctx := context.Background()
+	if _, ok := RequestFromContext(ctx); ok {
+		t.Error("got a request outside of a handler")
+	}
+	if HeaderFromContext(ctx) != nil || RemoteAddrFromContext(ctx) != "" || ResponseHeaderFromContext(ctx) != nil {
+		t.Error("got request details outside of a handler")
+	}
+}
+
+func TestContextFirstSignatures(t *testing.T) {
+	tests := []struct {
+		name string
+		hdl  RPCHandler
+		ok   bool
+	}{
+		{"context only", func(ctx context.Context) error { return nil }, true},
+		{"context and input", func(ctx context.Context, in testGetRequest) error { return nil }, true},
+		{"context and output", func(ctx context.Context, in *testGetRequest) (*testItem, error) { return nil, nil }, true},
+		{"context and request", func(ctx context.Context, r *http.Request) error { return nil }, true},
+		{"context after request", func(r *http.Request, ctx context.Context) error { return nil }, false},
+		{"context after input", func(in testGetRequest, ctx context.Context) error { return nil }, false},
+		{"two contexts", func(ctx, ctx2 context.Context) error { return nil }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if p := recover(); (p == nil) != tt.ok {
+					t.Errorf("got panic %v, want ok %v", p, tt.ok)
+				}
+			}()
+			NewRouter().MethodFunc(http.MethodGet, "/items/{id}", tt.hdl)
+		})
+	}
+}
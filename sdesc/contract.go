@@ -0,0 +1,308 @@
+package sdesc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// ContractError describes a response that the route's operation
+// in the OpenAPI document doesn't describe.
+type ContractError struct {
+	// Method and Pattern are the route's ones.
+	Method  string
+	Pattern string
+	Status  int
+	// Problems list the differences between the response and the spec.
+	Problems []string
+}
+
+func (e *ContractError) Error() string {
+	return fmt.Sprintf("response %d of '%v %v' breaks the contract: %v",
+		e.Status, e.Method, e.Pattern, strings.Join(e.Problems, "; "))
+}
+
+// ResponseValidator is a middleware that checks the responses against
+// the operations of the OpenAPI documents generated by pontoongen:
+// undocumented statuses and media types, bodies not matching the schemas,
+// object properties the schemas don't list and values of the schemas
+// that describe nothing, i.e. the ones of interface{} results.
+//
+// It's meant for tests and development: the responses are passed
+// through as is, and the differences are reported once the handler
+// is done. Like the RequestValidator, it should be one of the
+// Router's middlewares:
+//
+//	v, err := sdesc.NewResponseValidator(nil, svc)
+//	r := sdesc.NewRouter(sdesc.WithGlobalMiddlewares(v.Middleware))
+//
+// Bodies of media types that openapi3filter cannot decode,
+// i.e. streams, aren't checked. WebSockets aren't checked at all.
+type ResponseValidator struct {
+	// routes are keyed by the method and the path pattern.
+	routes map[string]*routers.Route
+	report func(*http.Request, *ContractError)
+}
+
+// NewResponseValidator loads the OpenAPI documents of the Services;
+// every Service should implement OpenAPIProvider.
+// The responses breaking the contract are passed to report;
+// if it's nil, they're logged. See sdesctest.NewResponseValidator
+// for the one failing the tests.
+func NewResponseValidator(report func(*http.Request, *ContractError), svcs ...Service) (*ResponseValidator, error) {
+	routes, err := loadRoutes(svcs)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		report = logContractError
+	}
+	return &ResponseValidator{routes: routes, report: report}, nil
+}
+
+func logContractError(r *http.Request, err *ContractError) {
+	log.Printf("sdesc: CONTRACT BROKEN by %v %v: %v", r.Method, r.URL.Path, err)
+}
+
+// Middleware checks the responses of the described routes;
+// responses of other routes are passed as is.
+func (v *ResponseValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ri, ok := RouteFromContext(r.Context())
+		if !ok || strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		route, ok := v.routes[ri.Method+" "+ri.Pattern]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &contractWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		if !cw.wroteHeader {
+			cw.status = http.StatusOK
+		}
+
+		problems := v.check(r, route, cw)
+		if len(problems) > 0 {
+			v.report(r, &ContractError{
+				Method:   ri.Method,
+				Pattern:  ri.Pattern,
+				Status:   cw.status,
+				Problems: problems,
+			})
+		}
+	})
+}
+
+func (v *ResponseValidator) check(r *http.Request, route *routers.Route, w *contractWriter) []string {
+	rsp := route.Operation.Responses.Get(w.status)
+	if rsp == nil {
+		rsp = route.Operation.Responses.Default()
+	}
+	if rsp == nil || rsp.Value == nil || (rsp.Value.Description != nil && *rsp.Value.Description == "" && len(rsp.Value.Content) == 0) {
+		// openapi3.NewResponses adds an empty default response
+		return []string{fmt.Sprintf("status %d isn't described", w.status)}
+	}
+	if w.written == 0 || len(rsp.Value.Content) == 0 {
+		return nil
+	}
+
+	in := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request: r,
+			Route:   route,
+		},
+		Status: w.status,
+		Header: w.Header(),
+		Body:   io.NopCloser(bytes.NewReader(w.body.Bytes())),
+		Options: &openapi3filter.Options{
+			MultiError:          true,
+			ExcludeResponseBody: !w.capture || w.truncated,
+		},
+	}
+	err := openapi3filter.ValidateResponse(r.Context(), in)
+	if err != nil {
+		return responseProblems(err)
+	}
+
+	ct := w.Header().Get("Content-Type")
+	mt := rsp.Value.Content.Get(ct)
+	switch {
+	case mt == nil:
+		return []string{fmt.Sprintf("media type '%v' isn't described", ct)}
+	case mt.Schema == nil || mt.Schema.Value == nil || !w.capture || w.truncated || !isJSON(ct):
+		return nil
+	}
+
+	var body interface{}
+	if json.Unmarshal(w.body.Bytes(), &body) != nil {
+		// reported by ValidateResponse
+		return nil
+	}
+	ret := []string{}
+	undescribed(mt.Schema.Value, body, "body", &ret)
+	return ret
+}
+
+// responseProblems lists the errors of openapi3filter.ValidateResponse.
+func responseProblems(err error) []string {
+	re, ok := err.(*openapi3filter.ResponseError)
+	if !ok {
+		return []string{err.Error()}
+	}
+	var errs openapi3.MultiError
+	switch e := re.Err.(type) {
+	case nil:
+		return []string{re.Reason}
+	case openapi3.MultiError:
+		errs = e
+	default:
+		errs = openapi3.MultiError{e}
+	}
+
+	ret := []string{}
+	for _, err := range errs {
+		se, ok := err.(*openapi3.SchemaError)
+		if !ok {
+			ret = append(ret, re.Reason+": "+err.Error())
+			continue
+		}
+		ret = append(ret, strings.Join(append([]string{"body"}, se.JSONPointer()...), ".")+": "+se.Reason)
+	}
+	return ret
+}
+
+// undescribed lists the parts of the value the schema doesn't describe:
+// the properties that objects' schemas don't list
+// and the values of the empty schemas.
+func undescribed(s *openapi3.Schema, v interface{}, path string, ret *[]string) {
+	if v == nil {
+		return
+	}
+	ss := allOf(s)
+	described := false
+	for _, s := range ss {
+		if len(s.OneOf) > 0 || len(s.AnyOf) > 0 || s.Not != nil {
+			// the matching alternative is unknown
+			return
+		}
+		described = described || s.Type != "" || len(s.Properties) > 0 || s.Items != nil ||
+			s.AdditionalProperties != nil || s.AdditionalPropertiesAllowed != nil || len(s.Enum) > 0
+	}
+	if !described {
+		*ret = append(*ret, path+": value isn't described")
+		return
+	}
+
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	props:
+		for _, k := range keys {
+			additional := false
+			for _, s := range ss {
+				if p := s.Properties[k]; p != nil && p.Value != nil {
+					undescribed(p.Value, vv[k], path+"."+k, ret)
+					continue props
+				}
+			}
+			for _, s := range ss {
+				if s.AdditionalProperties != nil && s.AdditionalProperties.Value != nil {
+					undescribed(s.AdditionalProperties.Value, vv[k], path+"."+k, ret)
+					continue props
+				}
+				additional = additional || (s.AdditionalPropertiesAllowed != nil && *s.AdditionalPropertiesAllowed)
+			}
+			if !additional {
+				*ret = append(*ret, path+"."+k+": property isn't described")
+			}
+		}
+	case []interface{}:
+		for _, s := range ss {
+			if s.Items == nil || s.Items.Value == nil {
+				continue
+			}
+			for i, item := range vv {
+				undescribed(s.Items.Value, item, fmt.Sprintf("%v.%d", path, i), ret)
+			}
+			return
+		}
+	}
+}
+
+// allOf returns the schema and the schemas it's composed of.
+func allOf(s *openapi3.Schema) []*openapi3.Schema {
+	ret := []*openapi3.Schema{s}
+	for _, ref := range s.AllOf {
+		if ref.Value != nil {
+			ret = append(ret, allOf(ref.Value)...)
+		}
+	}
+	return ret
+}
+
+func isJSON(contentType string) bool {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	return mt == MediaTypeJSON || strings.HasSuffix(mt, "+json")
+}
+
+// contractWriter passes the response through
+// and keeps a copy of the body to check.
+type contractWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+
+	// capture is true if the body's media type can be checked.
+	capture   bool
+	body      bytes.Buffer
+	truncated bool
+	written   int
+}
+
+func (w *contractWriter) WriteHeader(code int) {
+	if !w.wroteHeader && code >= http.StatusOK {
+		w.wroteHeader = true
+		w.status = code
+		mt, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		w.capture = openapi3filter.RegisteredBodyDecoder(mt) != nil
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *contractWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.written += len(b)
+	if w.capture && !w.truncated {
+		if w.body.Len()+len(b) > maxMemory {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *contractWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,70 @@
+package sdesc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResponseValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantCode int
+		want     []string
+	}{
+		{"valid", "/items/1", http.StatusOK, nil},
+		{"described error", "/items/missing", http.StatusNotFound, nil},
+		{"undescribed status", "/items/conflict", http.StatusConflict, []string{
+			"status 409 isn't described",
+		}},
+		{"undescribed property", "/items/extra", http.StatusOK, []string{
+			"body.extra: property isn't described",
+		}},
+		{"wrong type", "/items/number", http.StatusOK, []string{
+			`body.id: Field must be set to string or not be present`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*ContractError
+			svc := testItemsService()
+			v, err := NewResponseValidator(func(r *http.Request, err *ContractError) {
+				got = append(got, err)
+			}, svc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewRouter(WithGlobalMiddlewares(v.Middleware))
+			r.Register(svc)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if w.Code != tt.wantCode {
+				t.Fatalf("got status %v, want %v; body %s", w.Code, tt.wantCode, w.Body)
+			}
+
+			if tt.want == nil {
+				if len(got) != 0 {
+					t.Errorf("got reports %v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %v reports, want 1", len(got))
+			}
+			ce := got[0]
+			if ce.Method != http.MethodGet || ce.Pattern != "/items/{id}" || ce.Status != tt.wantCode {
+				t.Errorf("got report of %v %v %v", ce.Status, ce.Method, ce.Pattern)
+			}
+			if !reflect.DeepEqual(ce.Problems, tt.want) {
+				t.Errorf("got problems %q, want %q", ce.Problems, tt.want)
+			}
+			if !strings.Contains(ce.Error(), tt.want[0]) {
+				t.Errorf("error %q doesn't mention the problem", ce.Error())
+			}
+		})
+	}
+}
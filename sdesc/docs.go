@@ -0,0 +1,201 @@
+package sdesc
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// OpenAPIProvider is implemented by Services
+// with a spec generated by pontoongen.
+type OpenAPIProvider interface {
+	OpenAPI() string
+}
+
+//go:embed docs.html
+var docsPage []byte
+
+// swaggerUI is the Swagger UI bundle, see swaggerui/LICENSE.
+//
+//go:embed swaggerui/swagger-ui-bundle.js swaggerui/swagger-ui.css
+var swaggerUI embed.FS
+
+// DocsHandler serves a merged OpenAPI document of the Services
+// and Swagger UI to browse and try it; Swagger UI is embedded
+// and needs no CDN.
+//
+// Given the prefix "/docs", it serves:
+//   - /docs/ - Swagger UI;
+//   - /docs/swagger-ui-bundle.js, /docs/swagger-ui.css - its assets;
+//   - /docs/openapi.json - the spec as JSON;
+//   - /docs/openapi.yaml - the spec as YAML.
+type DocsHandler struct {
+	prefix string
+	json   []byte
+	yaml   []byte
+}
+
+var _ http.Handler = &DocsHandler{}
+
+// NewDocsHandler merges OpenAPI documents of the Services.
+// Every Service should implement OpenAPIProvider.
+// Mount the handler at its prefix:
+//
+//	docs, err := sdesc.NewDocsHandler("/docs", svc1, svc2)
+//	mux.Handle("/docs/", docs)
+func NewDocsHandler(prefix string, svcs ...Service) (*DocsHandler, error) {
+	docs := make([]string, 0, len(svcs))
+	for _, s := range svcs {
+		p, ok := s.(OpenAPIProvider)
+		if !ok {
+			return nil, errors.Errorf("service %T has no OpenAPI() method - did you run pontoongen?", s)
+		}
+		docs = append(docs, p.OpenAPI())
+	}
+
+	merged, err := mergeOpenAPI(docs)
+	if err != nil {
+		return nil, errors.Wrap(err, "merging OpenAPI documents")
+	}
+
+	ret := &DocsHandler{
+		prefix: strings.TrimSuffix(prefix, "/"),
+	}
+	ret.json, err = json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling JSON")
+	}
+	ret.yaml, err = yaml.JSONToYAML(ret.json)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting JSON to YAML")
+	}
+	return ret, nil
+}
+
+func (h *DocsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutPrefix(r.URL.Path, h.prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch path {
+	case "":
+		http.Redirect(w, r, h.prefix+"/", http.StatusMovedPermanently)
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(docsPage)
+	case "/openapi.json":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(h.json)
+	case "/openapi.yaml":
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(h.yaml)
+	case "/swagger-ui-bundle.js", "/swagger-ui.css":
+		http.ServeFileFS(w, r, swaggerUI, "swaggerui"+path)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// mergeOpenAPI merges paths, components and tags of the documents.
+// Identical definitions are deduplicated, conflicting ones are reported.
+func mergeOpenAPI(docs []string) (map[string]interface{}, error) {
+	ret := map[string]interface{}{
+		"openapi":    "3.1.0",
+		"paths":      map[string]interface{}{},
+		"components": map[string]interface{}{},
+	}
+	titles := []string{}
+	tags := []interface{}{}
+	seenTags := map[string]bool{}
+
+	for i, d := range docs {
+		doc := map[string]interface{}{}
+		err := json.Unmarshal([]byte(d), &doc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding document #%v", i)
+		}
+
+		if v, ok := doc["openapi"].(string); ok {
+			ret["openapi"] = v
+		}
+		if info, ok := doc["info"].(map[string]interface{}); ok {
+			if t, ok := info["title"].(string); ok && !contains(titles, t) {
+				titles = append(titles, t)
+			}
+		}
+
+		paths, _ := doc["paths"].(map[string]interface{})
+		err = mergeMaps(ret["paths"].(map[string]interface{}), paths, 2, "paths")
+		if err != nil {
+			return nil, err
+		}
+		comps, _ := doc["components"].(map[string]interface{})
+		err = mergeMaps(ret["components"].(map[string]interface{}), comps, 2, "components")
+		if err != nil {
+			return nil, err
+		}
+
+		docTags, _ := doc["tags"].([]interface{})
+		for _, t := range docTags {
+			name, _ := t.(map[string]interface{})["name"].(string)
+			if seenTags[name] {
+				continue
+			}
+			seenTags[name] = true
+			tags = append(tags, t)
+		}
+	}
+
+	ret["info"] = map[string]interface{}{
+		"title":   strings.Join(titles, ", "),
+		"version": "1-autogen",
+	}
+	if len(tags) > 0 {
+		ret["tags"] = tags
+	}
+	return ret, nil
+}
+
+// mergeMaps copies src to dst, descending depth levels deep;
+// values deeper than that should be equal if they're in both maps.
+func mergeMaps(dst, src map[string]interface{}, depth int, path string) error {
+	for k, v := range src {
+		cur, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		if depth > 1 {
+			curMap, ok1 := cur.(map[string]interface{})
+			vMap, ok2 := v.(map[string]interface{})
+			if ok1 && ok2 {
+				err := mergeMaps(curMap, vMap, depth-1, path+"."+k)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !reflect.DeepEqual(cur, v) {
+			return fmt.Errorf("conflicting definitions of '%v.%v'", path, k)
+		}
+	}
+	return nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
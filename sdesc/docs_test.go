@@ -0,0 +1,70 @@
+package sdesc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testDocsService struct{}
+
+func (testDocsService) RegisterHTTP(HTTPRouter)         {}
+func (testDocsService) ServiceOptions() []ServiceOption { return nil }
+
+func (testDocsService) OpenAPI() string {
+	return `{"openapi":"3.0.3","info":{"title":"Items","version":"1"},"paths":{}}`
+}
+
+func TestDocsHandler(t *testing.T) {
+	h, err := NewDocsHandler("/docs", testDocsService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		wantType string
+		wantBody string
+	}{
+		{"/docs/", "text/html", `src="swagger-ui-bundle.js"`},
+		{"/docs/swagger-ui-bundle.js", "javascript", "SwaggerUIBundle"},
+		{"/docs/swagger-ui.css", "text/css", ".swagger-ui"},
+		{"/docs/openapi.json", "application/json", `"title": "Items"`},
+		{"/docs/openapi.yaml", "application/yaml", "title: Items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rsp, err := srv.Client().Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rsp.Body.Close()
+			body, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rsp.StatusCode != http.StatusOK {
+				t.Fatalf("got status %v, want 200", rsp.StatusCode)
+			}
+			if ct := rsp.Header.Get("Content-Type"); !strings.Contains(ct, tt.wantType) {
+				t.Errorf("got Content-Type %q, want %q", ct, tt.wantType)
+			}
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("body doesn't contain %q", tt.wantBody)
+			}
+		})
+	}
+
+	rsp, err := srv.Client().Get(srv.URL + "/docs/LICENSE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %v for an unknown file, want 404", rsp.StatusCode)
+	}
+}
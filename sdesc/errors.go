@@ -0,0 +1,135 @@
+package sdesc
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// StatusCoder is implemented by errors that should be
+// reported with a specific HTTP status code.
+// Router finds it in the error chain via errors.As;
+// errors without a StatusCoder are reported as 500
+// with a generic message; the error itself is logged.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Error is an error with an HTTP status code.
+type Error struct {
+	Code    int
+	Message string
+	// Err is an optional cause of the error.
+	Err error
+}
+
+var _ StatusCoder = &Error{}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// StatusCode returns the error's HTTP status code.
+func (e *Error) StatusCode() int {
+	return e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError returns an error with an HTTP status code.
+func NewError(code int, msg string) error {
+	return &Error{Code: code, Message: msg}
+}
+
+// Errorf formats an error with an HTTP status code.
+func Errorf(code int, format string, args ...interface{}) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// WithStatus annotates err with an HTTP status code.
+// It returns nil if err is nil.
+func WithStatus(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// BadRequest returns an error reported as 400 Bad Request.
+func BadRequest(msg string) error {
+	return NewError(http.StatusBadRequest, msg)
+}
+
+// Unauthorized returns an error reported as 401 Unauthorized.
+func Unauthorized(msg string) error {
+	return NewError(http.StatusUnauthorized, msg)
+}
+
+// Forbidden returns an error reported as 403 Forbidden.
+func Forbidden(msg string) error {
+	return NewError(http.StatusForbidden, msg)
+}
+
+// NotFound returns an error reported as 404 Not Found.
+func NotFound(msg string) error {
+	return NewError(http.StatusNotFound, msg)
+}
+
+// Conflict returns an error reported as 409 Conflict.
+func Conflict(msg string) error {
+	return NewError(http.StatusConflict, msg)
+}
+
+// Unprocessable returns an error reported as 422 Unprocessable Entity.
+func Unprocessable(msg string) error {
+	return NewError(http.StatusUnprocessableEntity, msg)
+}
+
+// TooManyRequests returns an error reported as 429 Too Many Requests.
+func TooManyRequests(msg string) error {
+	return NewError(http.StatusTooManyRequests, msg)
+}
+
+// Internal returns an error reported as 500 Internal Server Error.
+func Internal(msg string) error {
+	return NewError(http.StatusInternalServerError, msg)
+}
+
+// Unavailable returns an error reported as 503 Service Unavailable.
+func Unavailable(msg string) error {
+	return NewError(http.StatusServiceUnavailable, msg)
+}
+
+// StatusCode returns an HTTP status code for the error:
+// the one of the first StatusCoder in its chain or 500.
+func StatusCode(err error) int {
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		if code := sc.StatusCode(); code != 0 {
+			return code
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// errorMessage returns the message of the error for the client.
+// Server errors without a StatusCoder in their chain, like the ones
+// of the databases, aren't disclosed; they're logged instead.
+func errorMessage(r *http.Request, err error, code int) string {
+	var sc StatusCoder
+	if code < http.StatusInternalServerError || errors.As(err, &sc) {
+		return err.Error()
+	}
+	log.Printf("sdesc: error serving %v %v: %v", r.Method, r.URL.Path, err)
+	return http.StatusText(code)
+}
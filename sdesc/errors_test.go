@@ -0,0 +1,51 @@
+package sdesc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestWriteErrorMasksInternalErrors(t *testing.T) {
+	secret := errors.New(`pq: password authentication failed for user "admin"`)
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantMsg  string
+	}{
+		{"plain", secret, http.StatusInternalServerError, "Internal Server Error"},
+		{"wrapped", errors.Wrap(secret, "loading user"), http.StatusInternalServerError, "Internal Server Error"},
+		{"status coder", Unavailable("try again later"), http.StatusServiceUnavailable, "try again later"},
+		{"client error", NotFound("no such item"), http.StatusNotFound, "no such item"},
+		{"annotated", WithStatus(secret, http.StatusBadGateway), http.StatusBadGateway, secret.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+
+			w := httptest.NewRecorder()
+			writeError(w, r, tt.err)
+			var rsp errorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantCode || rsp.Error != tt.wantMsg {
+				t.Errorf("JSON: got %v %q, want %v %q", w.Code, rsp.Error, tt.wantCode, tt.wantMsg)
+			}
+
+			w = httptest.NewRecorder()
+			writeProblem(w, r, tt.err)
+			var pd ProblemDetails
+			if err := json.Unmarshal(w.Body.Bytes(), &pd); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantCode || pd.Detail != tt.wantMsg {
+				t.Errorf("problem: got %v %q, want %v %q", w.Code, pd.Detail, tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package sdesc
+
+import (
+	"context"
+	"net/http"
+)
+
+// Handle registers a handler of the request's input In
+// that responds with Out.
+// Unlike HTTPRouter.MethodFunc, the handler's signature
+// is checked at compile time:
+//
+//	sdesc.Handle(mux, http.MethodGet, "/v1/items/{id}", h.getItem)
+func Handle[In, Out any](r HTTPRouter, method, pattern string, fn func(*http.Request, In) (Out, error), opts ...RouteOption) {
+	r.MethodFunc(method, pattern, fn, opts...)
+}
+
+// HandleCtx registers a context-first handler of the request's input In
+// that responds with Out; see Handle.
+func HandleCtx[In, Out any](r HTTPRouter, method, pattern string, fn func(context.Context, In) (Out, error), opts ...RouteOption) {
+	r.MethodFunc(method, pattern, fn, opts...)
+}
@@ -0,0 +1,360 @@
+package sdesc
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	typeRequest        = reflect.TypeOf((*http.Request)(nil))
+	typeResponseWriter = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+	typeError          = reflect.TypeOf((*error)(nil)).Elem()
+	typeContext        = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
+
+type paramKind int
+
+const (
+	paramInput paramKind = iota
+	paramRequest
+	paramResponseWriter
+	paramContext
+	paramRecv
+	paramSend
+)
+
+// rpcHandler is an http.Handler that calls an RPCHandler via reflection.
+type rpcHandler struct {
+	fn     reflect.Value
+	params []paramKind
+	cfg    HandlerConfig
+
+	// inType is the handler's input type, nil if there's no input.
+	inType reflect.Type
+	binder *binder
+
+	hasOut            bool
+	hasResponseWriter bool
+	// stream is true if the output is a channel
+	// streamed as SSE or NDJSON.
+	stream bool
+
+	// socket is true for WebSocketHandlers;
+	// recvType and sendType are the types of their messages.
+	socket             bool
+	recvType, sendType reflect.Type
+	// origins are allowed to open the WebSocket.
+	origins []string
+
+	// codecs encode the bodies of the Service's media types,
+	// the first one is the default.
+	codecs []Codec
+
+	// serve replaces the reflective call
+	// for the routes generated by pontoongen.
+	serve func(http.ResponseWriter, *http.Request) error
+}
+
+// newRPCHandler checks RPCHandler's signature and prepares it for the calls.
+// Accepted signatures mirror the ones pontoongen understands.
+func newRPCHandler(hdl RPCHandler, cfg HandlerConfig) (*rpcHandler, error) {
+	fn := reflect.ValueOf(hdl)
+	if fn.Kind() != reflect.Func {
+		return nil, errors.Errorf("handler should be a func, got '%T'", hdl)
+	}
+	if fn.IsNil() {
+		return nil, errors.New("handler is nil")
+	}
+	t := fn.Type()
+	if t.IsVariadic() {
+		return nil, errors.New("handler cannot be variadic")
+	}
+
+	ret := &rpcHandler{fn: fn, cfg: cfg}
+
+	for i := 0; i < t.NumIn(); i++ {
+		p := t.In(i)
+		switch p {
+		case typeRequest:
+			ret.params = append(ret.params, paramRequest)
+			continue
+		case typeResponseWriter:
+			ret.hasResponseWriter = true
+			ret.params = append(ret.params, paramResponseWriter)
+			continue
+		case typeContext:
+			if i != 0 {
+				return nil, errors.New("context.Context should be the first parameter")
+			}
+			ret.params = append(ret.params, paramContext)
+			continue
+		}
+		if p.Kind() == reflect.Chan {
+			switch {
+			case p.ChanDir() == reflect.RecvDir && ret.recvType == nil:
+				ret.recvType = p.Elem()
+				ret.params = append(ret.params, paramRecv)
+			case p.ChanDir() == reflect.SendDir && ret.sendType == nil:
+				ret.sendType = p.Elem()
+				ret.params = append(ret.params, paramSend)
+			default:
+				return nil, errors.Errorf("unexpected channel parameter '%v'", p)
+			}
+			continue
+		}
+
+		if ret.inType != nil {
+			return nil, errors.New("handler has more than one request type")
+		}
+		st := p
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		if st.Kind() != reflect.Struct {
+			return nil, errors.Errorf("input type '%v' should be a struct or a pointer to struct", p)
+		}
+		b, err := newBinder(st)
+		if err != nil {
+			return nil, errors.Wrapf(err, "input type '%v'", p)
+		}
+		ret.inType = p
+		ret.binder = b
+		ret.params = append(ret.params, paramInput)
+	}
+
+	switch t.NumOut() {
+	case 1:
+	case 2:
+		if t.Out(0) == typeError {
+			return nil, errors.New("handler's first result should not be an error")
+		}
+		ret.hasOut = true
+		if out := t.Out(0); out.Kind() == reflect.Chan {
+			if out.ChanDir()&reflect.RecvDir == 0 {
+				return nil, errors.Errorf("handler's result '%v' should be a receivable channel", out)
+			}
+			ret.stream = true
+		}
+	default:
+		return nil, errors.Errorf("handler should return (<out>, error) or error, got %v results", t.NumOut())
+	}
+	if t.Out(t.NumOut()-1) != typeError {
+		return nil, errors.New("handler's last result should be an error")
+	}
+
+	return ret, nil
+}
+
+// newSocketHandler checks WebSocketHandler's signature
+// and prepares it for the calls.
+func newSocketHandler(hdl WebSocketHandler, cfg HandlerConfig) (*rpcHandler, error) {
+	ret, err := newRPCHandler(hdl, cfg)
+	if err != nil {
+		return nil, err
+	}
+	n := len(ret.params)
+	if n < 3 || ret.params[0] != paramContext ||
+		ret.params[n-2] != paramRecv || ret.params[n-1] != paramSend ||
+		ret.hasOut || ret.hasResponseWriter {
+		return nil, errors.Errorf("handler should be func(context.Context, [<input>,] <-chan <client msg>, chan<- <server msg>) error, got '%v'", ret.fn.Type())
+	}
+	ret.socket = true
+	return ret, nil
+}
+
+func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tw := &trackingWriter{ResponseWriter: w}
+	w = tw
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		if p == http.ErrAbortHandler {
+			panic(p)
+		}
+		log.Printf("sdesc: panic serving %v: %v\n%s", r.URL.Path, p, debug.Stack())
+		if !tw.wroteHeader {
+			h.writeError(w, r, Internal("internal error"))
+		}
+	}()
+
+	if h.socket && !isWebSocketUpgrade(r) {
+		w.Header().Set("Upgrade", "websocket")
+		h.writeError(w, r, Errorf(http.StatusUpgradeRequired, "websocket upgrade required"))
+		return
+	}
+
+	var rc Codec
+	if h.hasOut && !h.hasResponseWriter {
+		// refuse unacceptable calls before serving them
+		cs := h.codecs
+		if h.stream {
+			cs = streamCodecs
+		}
+		var err error
+		rc, err = responseCodec(r, cs)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+
+	if h.serve != nil {
+		r = r.WithContext(withNegotiation(r.Context(), negotiation{h: h, resp: rc}))
+		err := h.serve(w, r)
+		if err != nil && !tw.wroteHeader {
+			h.writeError(w, r, timeoutError(r, err))
+		}
+		return
+	}
+
+	args := make([]reflect.Value, len(h.params))
+	ctxArg := -1
+	for i, p := range h.params {
+		switch p {
+		case paramRequest:
+			args[i] = reflect.ValueOf(r)
+		case paramResponseWriter:
+			args[i] = reflect.ValueOf(w)
+		case paramContext:
+			ctxArg = i
+			args[i] = reflect.ValueOf(withCall(r.Context(), r, w))
+		case paramRecv:
+			args[i] = reflect.MakeChan(reflect.ChanOf(reflect.BothDir, h.recvType), 0)
+		case paramSend:
+			args[i] = reflect.MakeChan(reflect.ChanOf(reflect.BothDir, h.sendType), 0)
+		case paramInput:
+			in, err := h.decodeInput(r)
+			if err != nil {
+				h.writeError(w, r, err)
+				return
+			}
+			args[i] = in
+		}
+	}
+
+	if h.socket {
+		n := len(args)
+		h.serveSocket(w, r, args[n-2], args[n-1], func(ctx context.Context) error {
+			args[ctxArg] = reflect.ValueOf(ctx)
+			res := h.fn.Call(args)
+			err, _ := res[0].Interface().(error)
+			return err
+		})
+		return
+	}
+
+	res := h.fn.Call(args)
+
+	if errV := res[len(res)-1]; !errV.IsNil() {
+		if tw.wroteHeader {
+			// handler has already started the response, nothing to do
+			return
+		}
+		h.writeError(w, r, timeoutError(r, errV.Interface().(error)))
+		return
+	}
+
+	if h.hasResponseWriter {
+		return
+	}
+	if !h.hasOut {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if h.stream {
+		writeStream(w, r, rc, res[0])
+		return
+	}
+	h.writeResult(w, r, rc, res[0].Interface())
+}
+
+// writeResult encodes the handler's output with the negotiated codec.
+func (h *rpcHandler) writeResult(w http.ResponseWriter, r *http.Request, c Codec, v interface{}) {
+	body, err := c.Marshal(v)
+	if err != nil {
+		h.writeError(w, r, errors.Wrap(err, "encoding response"))
+		return
+	}
+	if len(h.codecs) > 1 {
+		w.Header().Add("Vary", "Accept")
+	}
+	w.Header().Set("Content-Type", c.MediaType())
+	w.WriteHeader(http.StatusOK)
+	// the headers are already sent, nowhere to report the error
+	_, _ = w.Write(body)
+}
+
+// timeoutError reports handler's context.DeadlineExceeded
+// as 504 if it's caused by the route's timeout.
+func timeoutError(r *http.Request, err error) error {
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) && r.Context().Err() == context.DeadlineExceeded {
+		return WithStatus(err, http.StatusGatewayTimeout)
+	}
+	return err
+}
+
+// writeError reports the error in the format chosen by HandlerConfig.
+func (h *rpcHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if h.cfg.ProblemDetails() {
+		writeProblem(w, r, err)
+		return
+	}
+	writeError(w, r, err)
+}
+
+// decodeInput creates a value of handler's input type
+// and fills it from the request.
+func (h *rpcHandler) decodeInput(r *http.Request) (reflect.Value, error) {
+	isPtr := h.inType.Kind() == reflect.Ptr
+	st := h.inType
+	if isPtr {
+		st = st.Elem()
+	}
+	in := reflect.New(st)
+
+	err := h.binder.bind(r, in, h.codecs)
+	if err != nil {
+		var sc StatusCoder
+		if !errors.As(err, &sc) {
+			err = WithStatus(err, http.StatusBadRequest)
+		}
+		return reflect.Value{}, err
+	}
+
+	if isPtr {
+		return in, nil
+	}
+	return in.Elem(), nil
+}
+
+// trackingWriter remembers whether the response was started.
+type trackingWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *trackingWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to reach the original writer.
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
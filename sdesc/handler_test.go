@@ -0,0 +1,256 @@
+package sdesc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type testItem struct {
+	ID string `json:"id"`
+}
+
+type testGetRequest struct {
+	ID string `in:"path=id"`
+}
+
+type testJSONRequest struct {
+	Name string `json:"name"`
+}
+
+func TestRouterHandlerShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		hdl      RPCHandler
+		method   string
+		target   string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "request only",
+			hdl: func(r *http.Request) (*testItem, error) {
+				return &testItem{ID: r.URL.Query().Get("id")}, nil
+			},
+			target:   "/items/x?id=1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":"1"}`,
+		},
+		{
+			name: "request and input",
+			hdl: func(r *http.Request, req testGetRequest) (*testItem, error) {
+				return &testItem{ID: req.ID}, nil
+			},
+			target:   "/items/42",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":"42"}`,
+		},
+		{
+			name: "pointer input",
+			hdl: func(r *http.Request, req *testGetRequest) (*testItem, error) {
+				return &testItem{ID: req.ID}, nil
+			},
+			target:   "/items/42",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":"42"}`,
+		},
+		{
+			name: "JSON body input",
+			hdl: func(r *http.Request, req testJSONRequest) (*testItem, error) {
+				return &testItem{ID: req.Name}, nil
+			},
+			method:   http.MethodPost,
+			target:   "/items/x",
+			body:     `{"name":"posted"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"id":"posted"}`,
+		},
+		{
+			name: "context first",
+			hdl: func(ctx context.Context, req testGetRequest) (*testItem, error) {
+				return &testItem{ID: req.ID}, ctx.Err()
+			},
+			target:   "/items/42",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":"42"}`,
+		},
+		{
+			name: "response writer",
+			hdl: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(http.StatusAccepted)
+				_, err := io.WriteString(w, "raw")
+				return err
+			},
+			target:   "/items/x",
+			wantCode: http.StatusAccepted,
+			wantBody: "raw",
+		},
+		{
+			name: "response writer with an error",
+			hdl: func(w http.ResponseWriter, r *http.Request, req testGetRequest) error {
+				return NotFound("no item " + req.ID)
+			},
+			target:   "/items/42",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":"no item 42"}`,
+		},
+		{
+			name: "error only",
+			hdl: func(r *http.Request, req testGetRequest) error {
+				return nil
+			},
+			target:   "/items/42",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "error only with an error",
+			hdl: func(r *http.Request) error {
+				return Conflict("changed concurrently")
+			},
+			target:   "/items/x",
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":"changed concurrently"}`,
+		},
+		{
+			name: "error without a status",
+			hdl: func(r *http.Request) (*testItem, error) {
+				return nil, errors.New("boom")
+			},
+			target:   "/items/x",
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"error":"Internal Server Error"}`,
+		},
+		{
+			name: "slice output",
+			hdl: func(r *http.Request) ([]testItem, error) {
+				return []testItem{{ID: "a"}, {ID: "b"}}, nil
+			},
+			target:   "/items/x",
+			wantCode: http.StatusOK,
+			wantBody: `[{"id":"a"},{"id":"b"}]`,
+		},
+		{
+			name: "map output",
+			hdl: func(r *http.Request) (map[string]testItem, error) {
+				return map[string]testItem{"a": {ID: "a"}}, nil
+			},
+			target:   "/items/x",
+			wantCode: http.StatusOK,
+			wantBody: `{"a":{"id":"a"}}`,
+		},
+		{
+			name: "interface output",
+			hdl: func(r *http.Request) (interface{}, error) {
+				return []int{1, 2}, nil
+			},
+			target:   "/items/x",
+			wantCode: http.StatusOK,
+			wantBody: `[1,2]`,
+		},
+		{
+			name: "any output",
+			hdl: func(r *http.Request) (any, error) {
+				return nil, nil
+			},
+			target:   "/items/x",
+			wantCode: http.StatusOK,
+			wantBody: `null`,
+		},
+		{
+			name: "panic",
+			hdl: func(r *http.Request) (*testItem, error) {
+				panic("boom")
+			},
+			target:   "/items/x",
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"error":"internal error"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := NewRouter()
+			r.MethodFunc(method, "/items/{id}", tt.hdl)
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			req, err := http.NewRequest(method, srv.URL+tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rsp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rsp.Body.Close()
+			body, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rsp.StatusCode != tt.wantCode {
+				t.Errorf("got status %v, want %v; body %s", rsp.StatusCode, tt.wantCode, body)
+			}
+			if got := strings.TrimSpace(string(body)); got != tt.wantBody {
+				t.Errorf("got body %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRouterJSONContentType(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/items", func(r *http.Request) ([]testItem, error) {
+		return nil, nil
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+	var v interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil || v != nil {
+		t.Errorf("got body %s, want null", w.Body.String())
+	}
+}
+
+func TestRouterRejectsBadSignatures(t *testing.T) {
+	tests := []struct {
+		name string
+		hdl  RPCHandler
+	}{
+		{"not a func", "handler"},
+		{"nil func", (func(*http.Request) error)(nil)},
+		{"no results", func(r *http.Request) {}},
+		{"no error", func(r *http.Request) *testItem { return nil }},
+		{"error first", func(r *http.Request) (error, *testItem) { return nil, nil }},
+		{"three results", func(r *http.Request) (*testItem, int, error) { return nil, 0, nil }},
+		{"variadic", func(r *http.Request, in ...testGetRequest) error { return nil }},
+		{"two inputs", func(a testGetRequest, b testJSONRequest) error { return nil }},
+		{"scalar input", func(r *http.Request, id string) error { return nil }},
+		{"context not first", func(r *http.Request, ctx context.Context) error { return nil }},
+		{"channel input", func(ctx context.Context, recv <-chan testItem) error { return nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("MethodFunc didn't panic")
+				}
+			}()
+			NewRouter().MethodFunc(http.MethodGet, "/items", tt.hdl)
+		})
+	}
+}
@@ -2,17 +2,38 @@ package sdesc
 
 import (
 	"net/http"
+	"time"
 )
 
 // HandlerConfig configures Handler that passes RPC calls through
 // to the Service.
 type HandlerConfig struct {
-	middlewares []func(http.Handler) http.Handler
+	middlewares    []func(http.Handler) http.Handler
+	problemDetails bool
+
+	securitySchemes map[string]SecurityScheme
+	security        []SecurityRequirement
+	mediaTypes      []string
 }
 
 func (c HandlerConfig) Clone() HandlerConfig {
+	ret := c
+	ret.middlewares = append([]func(http.Handler) http.Handler{}, c.middlewares...)
+	ret.security = append([]SecurityRequirement{}, c.security...)
+	ret.mediaTypes = append([]string{}, c.mediaTypes...)
+	ret.securitySchemes = make(map[string]SecurityScheme, len(c.securitySchemes))
+	for k, v := range c.securitySchemes {
+		ret.securitySchemes[k] = v
+	}
+	return ret
+}
+
+// NewHandlerConfig creates a HandlerConfig with given options applied.
+func NewHandlerConfig(opts ...ServiceOption) HandlerConfig {
 	ret := HandlerConfig{}
-	ret.middlewares = append(ret.middlewares, c.middlewares...)
+	for _, o := range opts {
+		o(&ret)
+	}
 	return ret
 }
 
@@ -22,6 +43,32 @@ func (c HandlerConfig) Middlewares() []func(http.Handler) http.Handler {
 	return c.middlewares
 }
 
+// ProblemDetails is true if errors should be reported
+// as RFC 7807 application/problem+json.
+func (c HandlerConfig) ProblemDetails() bool {
+	return c.problemDetails
+}
+
+// SecuritySchemes returns the security schemes declared by the Service.
+func (c HandlerConfig) SecuritySchemes() map[string]SecurityScheme {
+	return c.securitySchemes
+}
+
+// Security returns alternative security requirements
+// of the Service's routes; any of them should be satisfied.
+func (c HandlerConfig) Security() []SecurityRequirement {
+	return c.security
+}
+
+// MediaTypes returns the media types of the Service's bodies
+// in order of preference; application/json is the default.
+func (c HandlerConfig) MediaTypes() []string {
+	if len(c.mediaTypes) == 0 {
+		return []string{MediaTypeJSON}
+	}
+	return c.mediaTypes
+}
+
 type ServiceOption func(*HandlerConfig)
 
 // WithMiddlewares appends given middlewares that would be applied
@@ -31,3 +78,190 @@ func WithMiddlewares(mws ...func(http.Handler) http.Handler) ServiceOption {
 		c.middlewares = append(c.middlewares, mws...)
 	}
 }
+
+// WithProblemDetails makes the Service report every error,
+// including invalid requests and panics, as RFC 7807
+// application/problem+json.
+func WithProblemDetails() ServiceOption {
+	return func(c *HandlerConfig) {
+		c.problemDetails = true
+	}
+}
+
+// WithMediaTypes sets the media types of the Service's request
+// and response bodies, the first one being the default.
+// Responses are encoded according to the Accept header,
+// request bodies are decoded according to their Content-Type.
+// Every media type needs a Codec, see WithCodecs.
+//
+//	sdesc.WithMediaTypes(sdesc.MediaTypeJSON, sdesc.MediaTypeXML)
+//
+// Errors are always reported as JSON.
+func WithMediaTypes(types ...string) ServiceOption {
+	return func(c *HandlerConfig) {
+		c.mediaTypes = append(c.mediaTypes, types...)
+	}
+}
+
+// WithSecurityScheme declares a security scheme
+// that can be referred to by its name, i.e.
+//
+//	sdesc.WithSecurityScheme("jwt", sdesc.BearerAuth("JWT"))
+func WithSecurityScheme(name string, s SecurityScheme) ServiceOption {
+	return func(c *HandlerConfig) {
+		if c.securitySchemes == nil {
+			c.securitySchemes = map[string]SecurityScheme{}
+		}
+		c.securitySchemes[name] = s
+	}
+}
+
+// WithSecurity adds an alternative security requirement to every route
+// of the Service; routes' own requirements override it.
+// Every call adds a requirement; any of them should be satisfied.
+func WithSecurity(scheme string, scopes ...string) ServiceOption {
+	return func(c *HandlerConfig) {
+		c.security = append(c.security, SecurityRequirement{Scheme: scheme, Scopes: scopes})
+	}
+}
+
+// RouteConfig configures a single route.
+type RouteConfig struct {
+	middlewares []func(http.Handler) http.Handler
+	tags        []string
+	operationID string
+	deprecated  bool
+	security    []SecurityRequirement
+	noSecurity  bool
+	timeout     time.Duration
+	origins     []string
+}
+
+// NewRouteConfig creates a RouteConfig with given options applied.
+func NewRouteConfig(opts ...RouteOption) RouteConfig {
+	ret := RouteConfig{}
+	for _, o := range opts {
+		o(&ret)
+	}
+	return ret
+}
+
+// Middlewares returns middlewares that should be applied
+// to the route, inside of the Service's middlewares.
+func (c RouteConfig) Middlewares() []func(http.Handler) http.Handler {
+	return c.middlewares
+}
+
+// Tags returns additional OpenAPI tags of the route.
+func (c RouteConfig) Tags() []string {
+	return c.tags
+}
+
+// OperationID returns the route's operation ID override;
+// it's empty if the default one should be used.
+func (c RouteConfig) OperationID() string {
+	return c.operationID
+}
+
+// Deprecated is true if the route is deprecated.
+func (c RouteConfig) Deprecated() bool {
+	return c.deprecated
+}
+
+// Security returns alternative security requirements of the route;
+// any of them should be satisfied.
+func (c RouteConfig) Security() []SecurityRequirement {
+	return c.security
+}
+
+// NoSecurity is true if the route is public
+// regardless of the Service's security requirements.
+func (c RouteConfig) NoSecurity() bool {
+	return c.noSecurity
+}
+
+// Timeout returns the deadline of the route's handler, 0 if there's none.
+func (c RouteConfig) Timeout() time.Duration {
+	return c.timeout
+}
+
+// Origins returns the origins allowed to open the route's WebSocket
+// in addition to the same origin.
+func (c RouteConfig) Origins() []string {
+	return c.origins
+}
+
+// RouteOption configures a single route registered by HTTPRouter.MethodFunc.
+//
+// pontoongen reads RouteOptions passed to MethodFunc directly,
+// i.e. mux.MethodFunc("GET", "/v1/items", h.list, sdesc.WithTags("items")).
+type RouteOption func(*RouteConfig)
+
+// WithRouteMiddlewares appends middlewares that would be applied
+// to a single route.
+func WithRouteMiddlewares(mws ...func(http.Handler) http.Handler) RouteOption {
+	return func(c *RouteConfig) {
+		c.middlewares = append(c.middlewares, mws...)
+	}
+}
+
+// WithTags adds OpenAPI tags to the route,
+// in addition to the Service's tag.
+func WithTags(tags ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// WithOperationID overrides the route's default operation ID.
+func WithOperationID(id string) RouteOption {
+	return func(c *RouteConfig) {
+		c.operationID = id
+	}
+}
+
+// WithDeprecated marks the route as deprecated.
+// Its responses carry the 'Deprecation: true' header.
+func WithDeprecated() RouteOption {
+	return func(c *RouteConfig) {
+		c.deprecated = true
+	}
+}
+
+// WithRouteSecurity adds an alternative security requirement to the route,
+// overriding the Service's ones.
+// Every call adds a requirement; any of them should be satisfied.
+// Requests are checked by the Router's Authenticator;
+// the scheme should be declared by WithSecurityScheme.
+func WithRouteSecurity(scheme string, scopes ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.security = append(c.security, SecurityRequirement{Scheme: scheme, Scopes: scopes})
+	}
+}
+
+// WithoutSecurity makes the route public,
+// dropping the Service's security requirements.
+func WithoutSecurity() RouteOption {
+	return func(c *RouteConfig) {
+		c.noSecurity = true
+	}
+}
+
+// WithTimeout sets the deadline of the route's handler.
+// The handler's context.DeadlineExceeded errors are reported
+// as 504 Gateway Timeout.
+func WithTimeout(d time.Duration) RouteOption {
+	return func(c *RouteConfig) {
+		c.timeout = d
+	}
+}
+
+// WithOrigins allows browsers from the origins, i.e. "https://example.com",
+// to open the route's WebSocket; "*" allows any origin.
+// Only the same origin and clients without an Origin header,
+// like non-browser ones, are allowed by default.
+func WithOrigins(origins ...string) RouteOption {
+	return func(c *RouteConfig) {
+		c.origins = append(c.origins, origins...)
+	}
+}
@@ -0,0 +1,50 @@
+package sdesc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ContentTypeProblem is a media type of RFC 7807 error bodies.
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 error body,
+// used when the Service is configured WithProblemDetails.
+type ProblemDetails struct {
+	// Type is a URI reference that identifies the problem type.
+	Type string `json:"type"`
+	// Title is a short summary of the problem type.
+	Title string `json:"title"`
+	// Status is the HTTP status code.
+	Status int `json:"status"`
+	// Detail explains this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference of this occurrence.
+	Instance string `json:"instance,omitempty"`
+
+	// InvalidParams lists invalid request values, if any.
+	InvalidParams []FieldError `json:"invalid-params,omitempty"`
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	code := StatusCode(err)
+	rsp := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   errorMessage(r, err, code),
+		Instance: r.URL.Path,
+	}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		rsp.InvalidParams = verr.Fields
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(code)
+	// the headers are already sent, nowhere to report the error
+	_ = json.NewEncoder(w).Encode(rsp)
+}
@@ -0,0 +1,108 @@
+package sdesc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRouterProblemDetails(t *testing.T) {
+	guard := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("deny") != "" {
+				WriteError(w, r, Forbidden("denied by the middleware"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	svc := testService{
+		opts: []ServiceOption{WithProblemDetails(), WithMiddlewares(guard)},
+		fn: func(r HTTPRouter) {
+			r.MethodFunc(http.MethodGet, "/items/{id}", func(r *http.Request, in testGetRequest) (*testItem, error) {
+				switch in.ID {
+				case "missing":
+					return nil, NotFound("no such item")
+				case "broken":
+					return nil, errors.New("pq: connection refused")
+				case "panic":
+					panic("boom")
+				}
+				return &testItem{ID: in.ID}, nil
+			})
+			r.MethodFunc(http.MethodGet, "/search", func(r *http.Request, in testDefaultsRequest) error {
+				return nil
+			})
+		},
+	}
+	r := NewRouter()
+	r.Register(svc)
+
+	tests := []struct {
+		name   string
+		target string
+		want   ProblemDetails
+	}{
+		{
+			name:   "handler error",
+			target: "/items/missing",
+			want:   ProblemDetails{Type: "about:blank", Title: "Not Found", Status: 404, Detail: "no such item", Instance: "/items/missing"},
+		},
+		{
+			name:   "internal error",
+			target: "/items/broken",
+			want:   ProblemDetails{Type: "about:blank", Title: "Internal Server Error", Status: 500, Detail: "Internal Server Error", Instance: "/items/broken"},
+		},
+		{
+			name:   "panic",
+			target: "/items/panic",
+			want:   ProblemDetails{Type: "about:blank", Title: "Internal Server Error", Status: 500, Detail: "internal error", Instance: "/items/panic"},
+		},
+		{
+			name:   "middleware error",
+			target: "/items/1?deny=1",
+			want:   ProblemDetails{Type: "about:blank", Title: "Forbidden", Status: 403, Detail: "denied by the middleware", Instance: "/items/1"},
+		},
+		{
+			name:   "binding error",
+			target: "/search?local=here",
+			want: ProblemDetails{
+				Type: "about:blank", Title: "Bad Request", Status: 400, Instance: "/search",
+				Detail: "invalid request: header 'X-Region': value is required",
+				InvalidParams: []FieldError{
+					{Field: "Region", In: "header", Name: "X-Region", Reason: "value is required"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != tt.want.Status {
+				t.Errorf("got status %v, want %v", w.Code, tt.want.Status)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != ContentTypeProblem {
+				t.Errorf("got Content-Type %q, want %q", ct, ContentTypeProblem)
+			}
+			var got ProblemDetails
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	if ct := w.Header().Get("Content-Type"); w.Code != http.StatusOK || ct != MediaTypeJSON {
+		t.Errorf("got success %v of %q, want 200 of JSON", w.Code, ct)
+	}
+}
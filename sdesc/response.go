@@ -0,0 +1,34 @@
+package sdesc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// errorResponse is a JSON body of a failed call.
+type errorResponse struct {
+	Error string `json:"error"`
+	// Fields lists invalid request values, if any.
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	// the headers are already sent, nowhere to report the error
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError reports the error with its StatusCode.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	code := StatusCode(err)
+	rsp := errorResponse{Error: errorMessage(r, err, code)}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		rsp.Fields = verr.Fields
+	}
+	writeJSON(w, code, rsp)
+}
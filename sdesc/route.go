@@ -0,0 +1,115 @@
+package sdesc
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+// RouteInfo describes the route that matched the request.
+type RouteInfo struct {
+	// Method is the HTTP method of the route, i.e. "GET".
+	Method string
+	// Pattern is the registered path pattern, i.e. "/v1/items/{id}".
+	Pattern string
+	// OperationID is the operation ID of the route
+	// in the generated OpenAPI spec.
+	OperationID string
+	// Tags are additional tags set by WithTags.
+	Tags []string
+	// Deprecated is true if the route is deprecated.
+	Deprecated bool
+	// Security lists alternative security requirements of the route,
+	// including the ones inherited from the Service.
+	Security []SecurityRequirement
+}
+
+type ctxKeyRoute struct{}
+
+// ctxKeyHandler holds the *rpcHandler serving the route.
+type ctxKeyHandler struct{}
+
+// RouteFromContext returns the RouteInfo of the request
+// being served by the Router.
+// It's available to every middleware and handler of the route.
+func RouteFromContext(ctx context.Context) (RouteInfo, bool) {
+	ret, ok := ctx.Value(ctxKeyRoute{}).(RouteInfo)
+	return ret, ok
+}
+
+func newRouteInfo(method, pattern string, cfg HandlerConfig, rc RouteConfig) RouteInfo {
+	ret := RouteInfo{
+		Method:      method,
+		Pattern:     pattern,
+		OperationID: rc.OperationID(),
+		Tags:        rc.Tags(),
+		Deprecated:  rc.Deprecated(),
+		Security:    routeSecurity(cfg, rc),
+	}
+	if ret.OperationID == "" {
+		ret.OperationID = spec.OperationID(method, pattern)
+	}
+	return ret
+}
+
+// withRoute puts the RouteInfo and the route's handler
+// into the requests' context.
+func withRoute(h http.Handler, ri RouteInfo, hdl *rpcHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), ctxKeyRoute{}, ri)
+		ctx = context.WithValue(ctx, ctxKeyHandler{}, hdl)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WriteError reports the error like the handler of the request's route
+// would, i.e. as ProblemDetails if its Service is configured
+// WithProblemDetails. It's meant for the middlewares of the routes;
+// outside of them the error is reported as JSON.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if h, ok := r.Context().Value(ctxKeyHandler{}).(*rpcHandler); ok {
+		h.writeError(w, r, err)
+		return
+	}
+	writeError(w, r, err)
+}
+
+// routeSecurity returns effective security requirements of the route.
+func routeSecurity(cfg HandlerConfig, rc RouteConfig) []SecurityRequirement {
+	switch {
+	case rc.NoSecurity():
+		return nil
+	case len(rc.Security()) > 0:
+		return rc.Security()
+	}
+	return cfg.Security()
+}
+
+// withDeprecation marks responses of a deprecated route
+// with the Deprecation header.
+func withDeprecation(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		h.ServeHTTP(w, r)
+	})
+}
+
+// withTimeout sets the deadline of the request's context.
+func withTimeout(h http.Handler, d time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// chain wraps the handler in middlewares;
+// the first middleware is the outermost one.
+func chain(h http.Handler, mws []func(http.Handler) http.Handler) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
@@ -0,0 +1,117 @@
+package sdesc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRouteOptions(t *testing.T) {
+	tr := &testTracer{}
+	var got RouteInfo
+	r := NewRouter(WithGlobalMiddlewares(tr.mw("global")))
+	r.Register(testService{
+		opts: []ServiceOption{WithMiddlewares(tr.mw("service"))},
+		fn: func(r HTTPRouter) {
+			r.MethodFunc(http.MethodGet, "/items", func(r *http.Request) error {
+				got, _ = RouteFromContext(r.Context())
+				return tr.handler(r)
+			},
+				WithRouteMiddlewares(tr.mw("route 1"), tr.mw("route 2")),
+				WithTags("items", "public"),
+				WithOperationID("listItems"),
+				WithDeprecated())
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %v", w.Code)
+	}
+
+	wantCalls := []string{"global", "service", "route 1", "route 2", "handler"}
+	if !reflect.DeepEqual(tr.calls, wantCalls) {
+		t.Errorf("got calls %q, want %q", tr.calls, wantCalls)
+	}
+	if got.OperationID != "listItems" || !reflect.DeepEqual(got.Tags, []string{"items", "public"}) || !got.Deprecated {
+		t.Errorf("got %+v", got)
+	}
+	if w.Header().Get("Deprecation") != "true" {
+		t.Error("deprecated route's response has no Deprecation header")
+	}
+}
+
+func TestRouteTimeout(t *testing.T) {
+	const timeout = 20 * time.Millisecond
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		hdl      RPCHandler
+		wantCode int
+	}{
+		{
+			name:    "waits for the context",
+			timeout: timeout,
+			hdl: func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return nil
+				}
+			},
+			wantCode: http.StatusGatewayTimeout,
+		},
+		{
+			name:    "sleeps past the timeout",
+			timeout: timeout,
+			hdl: func(ctx context.Context) error {
+				time.Sleep(2 * timeout)
+				return ctx.Err()
+			},
+			wantCode: http.StatusGatewayTimeout,
+		},
+		{
+			name:    "in time",
+			timeout: timeout,
+			hdl: func(ctx context.Context) error {
+				if _, ok := ctx.Deadline(); !ok {
+					return NotFound("no deadline")
+				}
+				return ctx.Err()
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			// deadlines of the handler's own calls aren't the route's
+			name: "deadline of a call",
+			hdl: func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, time.Nanosecond)
+				defer cancel()
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []RouteOption
+			if tt.timeout > 0 {
+				opts = append(opts, WithTimeout(tt.timeout))
+			}
+			r := NewRouter()
+			r.MethodFunc(http.MethodGet, "/slow", tt.hdl, opts...)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %v, want %v; body %s", w.Code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package sdesc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+// Router is an HTTPRouter that serves RPCHandlers
+// using http.ServeMux.
+type Router struct {
+	mux         *http.ServeMux
+	svcs        []Service
+	middlewares []func(http.Handler) http.Handler
+	auth        Authenticator
+	// codecs are keyed by their media types.
+	codecs map[string]Codec
+}
+
+var _ HTTPRouter = &Router{}
+var _ http.Handler = &Router{}
+
+// RouterOption configures the Router.
+type RouterOption func(*Router)
+
+// WithGlobalMiddlewares appends middlewares that are applied
+// to every route of the Router, outside of the Services' middlewares.
+// They're applied to matched routes only, so RouteFromContext
+// is available to them.
+func WithGlobalMiddlewares(mws ...func(http.Handler) http.Handler) RouterOption {
+	return func(r *Router) {
+		r.middlewares = append(r.middlewares, mws...)
+	}
+}
+
+// NewRouter creates an empty Router.
+func NewRouter(opts ...RouterOption) *Router {
+	ret := &Router{
+		mux:    http.NewServeMux(),
+		codecs: defaultCodecs(),
+	}
+	for _, o := range opts {
+		o(ret)
+	}
+	return ret
+}
+
+// Register registers every endpoint of given Services,
+// configured by their ServiceOptions.
+// Service's middlewares wrap each of its routes in order,
+// the first one being the outermost.
+func (r *Router) Register(svcs ...Service) {
+	r.svcs = append(r.svcs, svcs...)
+	for _, s := range svcs {
+		s.RegisterHTTP(serviceRouter{
+			r:   r,
+			cfg: NewHandlerConfig(s.ServiceOptions()...),
+		})
+	}
+}
+
+// MethodFunc registers an RPCHandler for a method and a path pattern.
+// Pattern uses http.ServeMux syntax without the method, e.g. "/v1/items/{id}".
+// Handlers registered directly use the default HandlerConfig;
+// see Register.
+//
+// MethodFunc panics if the handler's signature is not supported,
+// if the Router has no Codec for the Service's media types
+// or if the route's security schemes aren't declared by the Service.
+func (r *Router) MethodFunc(method, pattern string, hdl RPCHandler, opts ...RouteOption) {
+	serviceRouter{r: r}.MethodFunc(method, pattern, hdl, opts...)
+}
+
+// WebSocket registers a WebSocketHandler for GET requests
+// of the path pattern; see MethodFunc.
+func (r *Router) WebSocket(pattern string, hdl WebSocketHandler, opts ...RouteOption) {
+	serviceRouter{r: r}.WebSocket(pattern, hdl, opts...)
+}
+
+// Route registers the routes of fn under the path prefix.
+func (r *Router) Route(prefix string, fn func(HTTPRouter)) {
+	serviceRouter{r: r}.Route(prefix, fn)
+}
+
+// With returns an HTTPRouter that applies the middlewares
+// to every route registered via it.
+func (r *Router) With(mws ...func(http.Handler) http.Handler) HTTPRouter {
+	return serviceRouter{r: r}.With(mws...)
+}
+
+// handle wraps the handler, outermost first, in global middlewares,
+// Service's middlewares, group's and route's middlewares,
+// route's timeout and the Authenticator.
+func (r *Router) handle(method, pattern string, hdl RPCHandler, cfg HandlerConfig, rc RouteConfig) {
+	h, err := newRPCHandler(hdl, cfg)
+	if err == nil && (h.recvType != nil || h.sendType != nil) {
+		err = errors.New("channel parameters are accepted by WebSocket handlers only")
+	}
+	if err != nil {
+		panic(fmt.Sprintf("sdesc: registering '%v %v': %v", method, pattern, err))
+	}
+	r.mount(method, pattern, h, cfg, rc)
+}
+
+// handleSocket registers a WebSocketHandler like handle.
+func (r *Router) handleSocket(pattern string, hdl WebSocketHandler, cfg HandlerConfig, rc RouteConfig) {
+	h, err := newSocketHandler(hdl, cfg)
+	if err != nil {
+		panic(fmt.Sprintf("sdesc: registering WebSocket '%v': %v", pattern, err))
+	}
+	r.mount(http.MethodGet, pattern, h, cfg, rc)
+}
+
+func (r *Router) mount(method, pattern string, h *rpcHandler, cfg HandlerConfig, rc RouteConfig) {
+	ri := newRouteInfo(method, pattern, cfg, rc)
+	h.origins = rc.Origins()
+
+	for _, mt := range cfg.MediaTypes() {
+		c, ok := r.codecs[mediaTypeKey(mt)]
+		if !ok {
+			panic(fmt.Sprintf("sdesc: registering '%v %v': no codec for media type '%v'", method, pattern, mt))
+		}
+		h.codecs = append(h.codecs, c)
+	}
+
+	var ret http.Handler = h
+	if len(ri.Security) > 0 {
+		if r.auth == nil {
+			panic(fmt.Sprintf("sdesc: registering '%v %v': route has security requirements, but the Router has no Authenticator", method, pattern))
+		}
+		challenges, err := authChallenges(ri.Security, cfg.SecuritySchemes())
+		if err != nil {
+			panic(fmt.Sprintf("sdesc: registering '%v %v': %v", method, pattern, err))
+		}
+		ret = withAuth(h, r.auth, ri.Security, challenges)
+	}
+	if rc.Timeout() > 0 {
+		ret = withTimeout(ret, rc.Timeout())
+	}
+	ret = chain(ret, rc.Middlewares())
+	ret = chain(ret, cfg.Middlewares())
+	ret = chain(ret, r.middlewares)
+	if rc.Deprecated() {
+		ret = withDeprecation(ret)
+	}
+	ret = withRoute(ret, ri, h)
+	r.mux.Handle(method+" "+pattern, ret)
+}
+
+// ServeDocs serves the merged OpenAPI document of every Service
+// registered so far and Swagger UI under the prefix, i.e. "/docs".
+// See DocsHandler.
+func (r *Router) ServeDocs(prefix string) error {
+	h, err := NewDocsHandler(prefix, r.svcs...)
+	if err != nil {
+		return err
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	r.mux.Handle("GET "+prefix+"/", h)
+	if prefix != "" {
+		r.mux.Handle("GET "+prefix, h)
+	}
+	return nil
+}
+
+// ServeHTTP dispatches the request to the registered handler.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+// serviceRouter registers endpoints of a single Service
+// or of a route group.
+type serviceRouter struct {
+	r   *Router
+	cfg HandlerConfig
+
+	// prefix and mws are set by the route groups.
+	prefix string
+	mws    []func(http.Handler) http.Handler
+}
+
+func (s serviceRouter) MethodFunc(method, pattern string, hdl RPCHandler, opts ...RouteOption) {
+	rc := NewRouteConfig(opts...)
+	rc.middlewares = append(append([]func(http.Handler) http.Handler{}, s.mws...), rc.middlewares...)
+	s.r.handle(method, spec.JoinPath(s.prefix, pattern), hdl, s.cfg, rc)
+}
+
+func (s serviceRouter) WebSocket(pattern string, hdl WebSocketHandler, opts ...RouteOption) {
+	rc := NewRouteConfig(opts...)
+	rc.middlewares = append(append([]func(http.Handler) http.Handler{}, s.mws...), rc.middlewares...)
+	s.r.handleSocket(spec.JoinPath(s.prefix, pattern), hdl, s.cfg, rc)
+}
+
+func (s serviceRouter) Route(prefix string, fn func(HTTPRouter)) {
+	s.prefix = spec.JoinPath(s.prefix, prefix)
+	fn(s)
+}
+
+func (s serviceRouter) With(mws ...func(http.Handler) http.Handler) HTTPRouter {
+	s.mws = append(append([]func(http.Handler) http.Handler{}, s.mws...), mws...)
+	return s
+}
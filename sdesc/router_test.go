@@ -0,0 +1,218 @@
+package sdesc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// testTracer records the middlewares and handlers in order of their calls.
+type testTracer struct {
+	calls []string
+}
+
+func (tr *testTracer) mw(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tr.calls = append(tr.calls, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (tr *testTracer) handler(r *http.Request) error {
+	tr.calls = append(tr.calls, "handler")
+	return nil
+}
+
+func TestRouterMiddlewareOrder(t *testing.T) {
+	tr := &testTracer{}
+	r := NewRouter(WithGlobalMiddlewares(tr.mw("global 1"), tr.mw("global 2")))
+	r.Register(testService{
+		opts: []ServiceOption{
+			WithMiddlewares(tr.mw("service 1")),
+			WithMiddlewares(tr.mw("service 2")),
+		},
+		fn: func(r HTTPRouter) {
+			r.MethodFunc(http.MethodGet, "/items", tr.handler)
+		},
+	})
+	r.Register(testService{
+		fn: func(r HTTPRouter) {
+			r.MethodFunc(http.MethodGet, "/other", tr.handler)
+		},
+	})
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/items", []string{"global 1", "global 2", "service 1", "service 2", "handler"}},
+		// middlewares of a Service don't apply to the others
+		{"/other", []string{"global 1", "global 2", "handler"}},
+	}
+	for _, tt := range tests {
+		tr.calls = nil
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%v: got status %v", tt.target, w.Code)
+		}
+		if !reflect.DeepEqual(tr.calls, tt.want) {
+			t.Errorf("%v: got calls %q, want %q", tt.target, tr.calls, tt.want)
+		}
+	}
+
+	// unmatched requests don't reach the middlewares
+	tr.calls = nil
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Code != http.StatusNotFound || len(tr.calls) != 0 {
+		t.Errorf("got status %v and calls %q for an unknown route", w.Code, tr.calls)
+	}
+}
+
+func TestRouteInfoInMiddlewares(t *testing.T) {
+	var got []RouteInfo
+	record := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ri, ok := RouteFromContext(r.Context())
+			if !ok {
+				t.Error("middleware got no RouteInfo")
+			}
+			got = append(got, ri)
+			next.ServeHTTP(w, r)
+		})
+	}
+	r := NewRouter(WithGlobalMiddlewares(record))
+	r.Register(testService{
+		opts: []ServiceOption{WithMiddlewares(record)},
+		fn: func(r HTTPRouter) {
+			r.MethodFunc(http.MethodGet, "/items/{id}", func(r *http.Request, in testGetRequest) error {
+				ri, _ := RouteFromContext(r.Context())
+				got = append(got, ri)
+				return nil
+			})
+		},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/42", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %v", w.Code)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %v RouteInfos, want 3", len(got))
+	}
+	for _, ri := range got {
+		if ri.Method != http.MethodGet || ri.Pattern != "/items/{id}" || ri.OperationID != "items__id__get" ||
+			len(ri.Tags) != 0 || ri.Deprecated || len(ri.Security) != 0 {
+			t.Errorf("got %+v", ri)
+		}
+	}
+
+	if _, ok := RouteFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); ok {
+		t.Error("got RouteInfo outside of the Router")
+	}
+}
+
+func TestWriteErrorInMiddlewares(t *testing.T) {
+	fail := func(err error) func(http.Handler) http.Handler {
+		return func(http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, err)
+			})
+		}
+	}
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantMsg  string
+	}{
+		{"status coder", TooManyRequests("slow down"), http.StatusTooManyRequests, "slow down"},
+		{"internal", errors.New("redis: connection pool timeout"), http.StatusInternalServerError, "Internal Server Error"},
+		{"annotated", WithStatus(errors.New("upstream is down"), http.StatusBadGateway), http.StatusBadGateway, "upstream is down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter()
+			r.Register(testService{
+				opts: []ServiceOption{WithMiddlewares(fail(tt.err))},
+				fn: func(r HTTPRouter) {
+					r.MethodFunc(http.MethodGet, "/items", func(r *http.Request) error {
+						t.Error("handler is called")
+						return nil
+					})
+				},
+			})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+			var rsp errorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantCode || rsp.Error != tt.wantMsg {
+				t.Errorf("got %v %q, want %v %q", w.Code, rsp.Error, tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestRouteGroups(t *testing.T) {
+	tr := &testTracer{}
+	patterns := map[string]string{}
+	handler := func(r *http.Request) error {
+		ri, _ := RouteFromContext(r.Context())
+		patterns[r.URL.Path] = ri.Pattern
+		return tr.handler(r)
+	}
+	r := NewRouter(WithGlobalMiddlewares(tr.mw("global")))
+	r.Register(testService{
+		opts: []ServiceOption{WithMiddlewares(tr.mw("service"))},
+		fn: func(r HTTPRouter) {
+			r.Route("/v1", func(r HTTPRouter) {
+				items := r.With(tr.mw("items"))
+				items.Route("/items", func(r HTTPRouter) {
+					r.MethodFunc(http.MethodGet, "", handler)
+					r.With(tr.mw("item")).MethodFunc(http.MethodGet, "/{id}", handler,
+						WithRouteMiddlewares(tr.mw("route")))
+				})
+				// With doesn't change the router it's called on
+				r.MethodFunc(http.MethodGet, "/health", handler)
+			})
+		},
+	})
+	r.With(tr.mw("unowned")).MethodFunc(http.MethodGet, "/unowned", handler)
+
+	tests := []struct {
+		target      string
+		wantPattern string
+		wantCalls   []string
+	}{
+		{"/v1/items", "/v1/items", []string{"global", "service", "items", "handler"}},
+		{"/v1/items/42", "/v1/items/{id}", []string{"global", "service", "items", "item", "route", "handler"}},
+		{"/v1/health", "/v1/health", []string{"global", "service", "handler"}},
+		{"/unowned", "/unowned", []string{"global", "unowned", "handler"}},
+	}
+	for _, tt := range tests {
+		tr.calls = nil
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%v: got status %v", tt.target, w.Code)
+			continue
+		}
+		if patterns[tt.target] != tt.wantPattern {
+			t.Errorf("%v: got pattern %q, want %q", tt.target, patterns[tt.target], tt.wantPattern)
+		}
+		if !reflect.DeepEqual(tr.calls, tt.wantCalls) {
+			t.Errorf("%v: got calls %q, want %q", tt.target, tr.calls, tt.wantCalls)
+		}
+	}
+}
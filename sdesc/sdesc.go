@@ -1,5 +1,7 @@
 package sdesc
 
+import "net/http"
+
 // Service is a collection of endpoints.
 type Service interface {
 	ServiceOptions() []ServiceOption
@@ -10,10 +12,26 @@ type Service interface {
 // func(*http.Request,<input type>) (<output type>,error)
 // or
 // func(*http.Request) (<out>,error)
-// waiting for generics /shrug
+// or, independent of net/http,
+// func(context.Context,<input type>) (<output type>,error);
+// see RequestFromContext for the request details.
+// An output of type <-chan T is streamed, element by element,
+// as Server-Sent Events or NDJSON, chosen by the Accept header;
+// the handler should close the channel once the request's context is done.
+// Use Handle and HandleCtx to check the signature at compile time.
 type RPCHandler interface{}
 
 // Router routes HTTP requests around.
 type HTTPRouter interface {
-	MethodFunc(method, pattern string, hdl RPCHandler)
+	MethodFunc(method, pattern string, hdl RPCHandler, opts ...RouteOption)
+	// WebSocket registers a WebSocketHandler for GET requests
+	// of the path pattern.
+	WebSocket(pattern string, hdl WebSocketHandler, opts ...RouteOption)
+
+	// Route registers the routes of fn under the path prefix,
+	// i.e. "/v1/items".
+	Route(prefix string, fn func(HTTPRouter))
+	// With returns an HTTPRouter that applies the middlewares
+	// to every route registered via it, inside of the Service's middlewares.
+	With(mws ...func(http.Handler) http.Handler) HTTPRouter
 }
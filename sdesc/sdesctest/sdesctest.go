@@ -0,0 +1,22 @@
+// Package sdesctest provides the helpers of sdesc for the tests.
+package sdesctest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/utrack/pontoon/sdesc"
+)
+
+// NewResponseValidator returns the sdesc.ResponseValidator
+// that fails the test on every response breaking the contract.
+func NewResponseValidator(t testing.TB, svcs ...sdesc.Service) *sdesc.ResponseValidator {
+	t.Helper()
+	ret, err := sdesc.NewResponseValidator(func(r *http.Request, err *sdesc.ContractError) {
+		t.Error(err)
+	}, svcs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
@@ -0,0 +1,83 @@
+package sdesctest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/utrack/pontoon/sdesc"
+)
+
+type testStatusRequest struct {
+	Code int `in:"path=code"`
+}
+
+type testService struct{}
+
+func (testService) ServiceOptions() []sdesc.ServiceOption { return nil }
+
+func (testService) RegisterHTTP(r sdesc.HTTPRouter) {
+	r.MethodFunc(http.MethodGet, "/status/{code}", func(r *http.Request, in testStatusRequest) (map[string]string, error) {
+		if in.Code == http.StatusOK {
+			return map[string]string{"status": "ok"}, nil
+		}
+		return nil, sdesc.NewError(in.Code, "failed")
+	})
+}
+
+func (testService) OpenAPI() string {
+	return `{
+  "openapi": "3.0.3",
+  "info": {"title": "Status", "version": "1"},
+  "paths": {"/status/{code}": {"get": {
+    "parameters": [{"name": "code", "in": "path", "required": true, "schema": {"type": "integer"}}],
+    "responses": {"200": {
+      "description": "success",
+      "content": {"application/json": {"schema": {"type": "object", "additionalProperties": {"type": "string"}}}}
+    }}
+  }}}
+}`
+}
+
+// recordingTB records the errors instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingTB) Helper() {}
+
+func (t *recordingTB) Error(args ...interface{}) {
+	for _, a := range args {
+		t.errors = append(t.errors, a.(error).Error())
+	}
+}
+
+func TestNewResponseValidator(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/status/200", ""},
+		{"/status/409", "status 409 isn't described"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			tb := &recordingTB{TB: t}
+			r := sdesc.NewRouter(sdesc.WithGlobalMiddlewares(NewResponseValidator(tb, testService{}).Middleware))
+			r.Register(testService{})
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if tt.want == "" {
+				if len(tb.errors) != 0 {
+					t.Errorf("got errors %q, want none", tb.errors)
+				}
+				return
+			}
+			if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], tt.want) {
+				t.Errorf("got errors %q, want %q", tb.errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package sdesc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SecurityRequirement requires the request to be authenticated
+// with the security scheme.
+// Scopes are used by OAuth2 and OpenID Connect schemes.
+type SecurityRequirement struct {
+	Scheme string
+	Scopes []string
+}
+
+// Types of the security schemes.
+const (
+	SecurityTypeHTTP   = "http"
+	SecurityTypeAPIKey = "apiKey"
+)
+
+// SecurityScheme describes a way to authenticate requests,
+// see OpenAPI's Security Scheme Object.
+// pontoongen understands schemes created by BearerAuth, BasicAuth
+// and APIKey* funcs only.
+type SecurityScheme struct {
+	// Type is either SecurityTypeHTTP or SecurityTypeAPIKey.
+	Type string
+	// Scheme is an HTTP auth scheme, i.e. "bearer".
+	Scheme string
+	// BearerFormat hints at the bearer token format, i.e. "JWT".
+	BearerFormat string
+	// In is a location of the API key: "header", "query" or "cookie".
+	In string
+	// Name is a name of the API key's header, query parameter or cookie.
+	Name string
+}
+
+// BearerAuth is an 'Authorization: Bearer <token>' scheme;
+// format hints at the token format, i.e. "JWT".
+func BearerAuth(format string) SecurityScheme {
+	return SecurityScheme{Type: SecurityTypeHTTP, Scheme: "bearer", BearerFormat: format}
+}
+
+// BasicAuth is an HTTP Basic authentication scheme.
+func BasicAuth() SecurityScheme {
+	return SecurityScheme{Type: SecurityTypeHTTP, Scheme: "basic"}
+}
+
+// APIKeyHeader is an API key passed in the header.
+func APIKeyHeader(name string) SecurityScheme {
+	return SecurityScheme{Type: SecurityTypeAPIKey, In: "header", Name: name}
+}
+
+// APIKeyQuery is an API key passed in the query parameter.
+func APIKeyQuery(name string) SecurityScheme {
+	return SecurityScheme{Type: SecurityTypeAPIKey, In: "query", Name: name}
+}
+
+// APIKeyCookie is an API key passed in the cookie.
+func APIKeyCookie(name string) SecurityScheme {
+	return SecurityScheme{Type: SecurityTypeAPIKey, In: "cookie", Name: name}
+}
+
+// Authenticator checks requests to the routes with security requirements.
+// It's set by WithAuthenticator.
+type Authenticator interface {
+	// Authenticate checks the request against the route's requirements;
+	// any of them should be satisfied.
+	// It returns the request's context, i.e. with the caller's identity
+	// added, or an error that rejects the request before the handler runs.
+	// Errors without a StatusCoder are reported as 401 Unauthorized;
+	// use Forbidden to report missing permissions.
+	Authenticate(r *http.Request, reqs []SecurityRequirement) (context.Context, error)
+}
+
+// AuthenticatorFunc is an Authenticator function.
+type AuthenticatorFunc func(r *http.Request, reqs []SecurityRequirement) (context.Context, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request, reqs []SecurityRequirement) (context.Context, error) {
+	return f(r, reqs)
+}
+
+// WithAuthenticator sets the Authenticator of the Router.
+// Routes with security requirements can't be registered without one.
+func WithAuthenticator(a Authenticator) RouterOption {
+	return func(r *Router) {
+		r.auth = a
+	}
+}
+
+// withAuth authenticates the requests before passing them to the handler.
+// Requests rejected with 401 Unauthorized get the challenges
+// of the schemes in the WWW-Authenticate header.
+func withAuth(h *rpcHandler, a Authenticator, reqs []SecurityRequirement, challenges []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := a.Authenticate(r, reqs)
+		if err != nil {
+			var sc StatusCoder
+			if !errors.As(err, &sc) {
+				err = WithStatus(err, http.StatusUnauthorized)
+			}
+			if errors.As(err, &sc) && sc.StatusCode() == http.StatusUnauthorized {
+				for _, c := range challenges {
+					w.Header().Add("WWW-Authenticate", c)
+				}
+			}
+			h.writeError(w, r, err)
+			return
+		}
+		if ctx != nil {
+			r = r.WithContext(ctx)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// authChallenges returns the WWW-Authenticate challenges
+// of the requirements' schemes, in order; it fails if a scheme
+// isn't declared by WithSecurityScheme.
+// API keys are challenged by the non-standard APIKey scheme.
+func authChallenges(reqs []SecurityRequirement, schemes map[string]SecurityScheme) ([]string, error) {
+	ret := []string{}
+	seen := map[string]bool{}
+	for _, req := range reqs {
+		s, ok := schemes[req.Scheme]
+		if !ok {
+			return nil, errors.Errorf("security scheme '%v' is not declared by WithSecurityScheme", req.Scheme)
+		}
+		var c string
+		switch {
+		case s.Type == SecurityTypeHTTP && strings.EqualFold(s.Scheme, "bearer"):
+			c = "Bearer"
+		case s.Type == SecurityTypeHTTP && strings.EqualFold(s.Scheme, "basic"):
+			c = `Basic realm="api", charset="UTF-8"`
+		case s.Type == SecurityTypeHTTP:
+			c = strings.ToUpper(s.Scheme[:1]) + s.Scheme[1:]
+		case s.Type == SecurityTypeAPIKey:
+			c = fmt.Sprintf("APIKey in=%q, name=%q", s.In, s.Name)
+		}
+		if c != "" && !seen[c] {
+			seen[c] = true
+			ret = append(ret, c)
+		}
+	}
+	return ret, nil
+}
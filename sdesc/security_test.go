@@ -0,0 +1,190 @@
+package sdesc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type ctxKeyTestUser struct{}
+
+// testAuthenticator accepts the bearer token "token" and the API key "key";
+// the token "banned" is forbidden.
+var testAuthenticator = AuthenticatorFunc(func(r *http.Request, reqs []SecurityRequirement) (context.Context, error) {
+	for _, req := range reqs {
+		switch {
+		case req.Scheme == "jwt" && r.Header.Get("Authorization") == "Bearer banned":
+			return nil, Forbidden("banned")
+		case req.Scheme == "jwt" && r.Header.Get("Authorization") == "Bearer token":
+			return context.WithValue(r.Context(), ctxKeyTestUser{}, "jwt user"), nil
+		case req.Scheme == "apiKey" && r.Header.Get("X-Api-Key") == "key":
+			return nil, nil
+		}
+	}
+	return nil, errors.New("not authenticated")
+})
+
+func testSecuredService() testService {
+	whoami := func(ctx context.Context) (*testItem, error) {
+		user, _ := ctx.Value(ctxKeyTestUser{}).(string)
+		return &testItem{ID: user}, nil
+	}
+	return testService{
+		opts: []ServiceOption{
+			WithSecurityScheme("jwt", BearerAuth("JWT")),
+			WithSecurityScheme("apiKey", APIKeyHeader("X-API-Key")),
+			WithSecurity("jwt"),
+		},
+		fn: func(r HTTPRouter) {
+			r.MethodFunc(http.MethodGet, "/me", whoami)
+			r.MethodFunc(http.MethodGet, "/either", whoami,
+				WithRouteSecurity("jwt"), WithRouteSecurity("apiKey"))
+			r.MethodFunc(http.MethodGet, "/public", whoami, WithoutSecurity())
+		},
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	r := NewRouter(WithAuthenticator(testAuthenticator))
+	r.Register(testSecuredService())
+
+	tests := []struct {
+		name          string
+		target        string
+		header        http.Header
+		wantCode      int
+		wantBody      string
+		wantChallenge []string
+	}{
+		{
+			name:          "anonymous",
+			target:        "/me",
+			wantCode:      http.StatusUnauthorized,
+			wantBody:      `{"error":"not authenticated"}`,
+			wantChallenge: []string{"Bearer"},
+		},
+		{
+			name:          "wrong scheme",
+			target:        "/me",
+			header:        http.Header{"X-Api-Key": {"key"}},
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{"Bearer"},
+		},
+		{
+			name:     "bearer",
+			target:   "/me",
+			header:   http.Header{"Authorization": {"Bearer token"}},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":"jwt user"}`,
+		},
+		{
+			name:     "forbidden",
+			target:   "/me",
+			header:   http.Header{"Authorization": {"Bearer banned"}},
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":"banned"}`,
+		},
+		{
+			name:     "alternative scheme",
+			target:   "/either",
+			header:   http.Header{"X-Api-Key": {"key"}},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":""}`,
+		},
+		{
+			name:          "anonymous with alternatives",
+			target:        "/either",
+			wantCode:      http.StatusUnauthorized,
+			wantChallenge: []string{"Bearer", `APIKey in="header", name="X-API-Key"`},
+		},
+		{
+			name:     "public",
+			target:   "/public",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":""}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for k, vs := range tt.header {
+				req.Header[k] = vs
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %v, want %v; body %s", w.Code, tt.wantCode, w.Body)
+			}
+			if got := w.Header().Values("WWW-Authenticate"); !reflect.DeepEqual(got, tt.wantChallenge) {
+				t.Errorf("got challenges %q, want %q", got, tt.wantChallenge)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody+"\n" {
+				t.Errorf("got body %s, want %s", w.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAuthChallenges(t *testing.T) {
+	got, err := authChallenges([]SecurityRequirement{{Scheme: "basic"}, {Scheme: "key"}, {Scheme: "basic2"}}, map[string]SecurityScheme{
+		"basic":  BasicAuth(),
+		"basic2": BasicAuth(),
+		"key":    APIKeyQuery("api_key"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`Basic realm="api", charset="UTF-8"`, `APIKey in="query", name="api_key"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRegisterRejectsBadSecurity(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []RouterOption
+		svc  testService
+	}{
+		{
+			name: "no Authenticator",
+			svc:  testSecuredService(),
+		},
+		{
+			name: "undeclared scheme",
+			opts: []RouterOption{WithAuthenticator(testAuthenticator)},
+			svc: testService{
+				opts: []ServiceOption{WithSecurityScheme("jwt", BearerAuth("JWT"))},
+				fn: func(r HTTPRouter) {
+					r.MethodFunc(http.MethodGet, "/me", func(r *http.Request) error { return nil },
+						WithRouteSecurity("jtw"))
+				},
+			},
+		},
+		{
+			name: "undeclared Service's scheme",
+			opts: []RouterOption{WithAuthenticator(testAuthenticator)},
+			svc: testService{
+				opts: []ServiceOption{WithSecurity("jwt")},
+				fn: func(r HTTPRouter) {
+					r.MethodFunc(http.MethodGet, "/me", func(r *http.Request) error { return nil })
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register didn't panic")
+				}
+			}()
+			NewRouter(tt.opts...).Register(tt.svc)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package sdesc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Media types of the streams returned by channel handlers.
+const (
+	MediaTypeEventStream = "text/event-stream"
+	MediaTypeNDJSON      = "application/x-ndjson"
+)
+
+// streamCodecs encode the elements of the streams,
+// Server-Sent Events being the default.
+var streamCodecs = []Codec{eventStreamCodec{}, ndjsonCodec{}}
+
+// eventStreamCodec encodes an element as an SSE message
+// with JSON data.
+type eventStreamCodec struct{}
+
+func (eventStreamCodec) MediaType() string {
+	return MediaTypeEventStream
+}
+
+func (eventStreamCodec) Marshal(v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(body) + 8)
+	buf.WriteString("data: ")
+	buf.Write(body)
+	buf.WriteString("\n\n")
+	return buf.Bytes(), nil
+}
+
+func (eventStreamCodec) Unmarshal([]byte, interface{}) error {
+	return errors.New("event streams cannot be decoded")
+}
+
+// ndjsonCodec encodes an element as a line of JSON.
+type ndjsonCodec struct{}
+
+func (ndjsonCodec) MediaType() string {
+	return MediaTypeNDJSON
+}
+
+func (ndjsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return JSONCodec{}.Marshal(v)
+}
+
+func (ndjsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// streamWriter writes the elements of a stream, flushing every one of them.
+type streamWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+	c  Codec
+}
+
+func newStreamWriter(w http.ResponseWriter, c Codec) *streamWriter {
+	w.Header().Set("Content-Type", c.MediaType())
+	w.Header().Set("Cache-Control", "no-cache")
+	// disables response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ret := &streamWriter{w: w, rc: http.NewResponseController(w), c: c}
+	_ = ret.rc.Flush()
+	return ret
+}
+
+func (s *streamWriter) send(v interface{}) error {
+	body, err := s.c.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "encoding stream element")
+	}
+	_, err = s.w.Write(body)
+	if err != nil {
+		return err
+	}
+	err = s.rc.Flush()
+	if err == http.ErrNotSupported {
+		return nil
+	}
+	return err
+}
+
+// writeStream sends the elements of the channel until it's closed
+// or the client goes away; see WriteStream.
+func writeStream(w http.ResponseWriter, r *http.Request, c Codec, ch reflect.Value) {
+	sw := newStreamWriter(w, c)
+	done := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(r.Context().Done())}
+	recv := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: ch}
+	for {
+		i, v, ok := reflect.Select([]reflect.SelectCase{done, recv})
+		if i == 1 && !ok {
+			return
+		}
+		var err error
+		if i == 1 {
+			err = sw.send(v.Interface())
+		}
+		if i == 0 || err != nil {
+			logStreamError(r, err)
+			go drainStream(ch)
+			return
+		}
+	}
+}
+
+// drainStream receives the rest of the elements until the channel
+// is closed, so the handler's sends don't block forever.
+func drainStream(ch reflect.Value) {
+	for {
+		if _, ok := ch.Recv(); !ok {
+			return
+		}
+	}
+}
+
+// WriteStream sends the elements of the channel returned by the handler
+// in the media type negotiated for the request, until the channel
+// is closed or the client goes away.
+// Once the client is gone, the rest of the elements are drained
+// in the background until the channel is closed; the handler should
+// stop sending and close the channel once the request's context is done.
+func WriteStream[T any](w http.ResponseWriter, r *http.Request, ch <-chan T) {
+	var c Codec = eventStreamCodec{}
+	if n, ok := negotiationFromContext(r.Context()); ok && n.resp != nil {
+		c = n.resp
+	}
+	sw := newStreamWriter(w, c)
+	var err error
+	for err == nil {
+		select {
+		case <-r.Context().Done():
+			err = r.Context().Err()
+		case v, ok := <-ch:
+			if !ok {
+				return
+			}
+			err = sw.send(v)
+		}
+	}
+	logStreamError(r, err)
+	go func() {
+		for range ch {
+		}
+	}()
+}
+
+func logStreamError(r *http.Request, err error) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	log.Printf("sdesc: streaming %v: %v", r.URL.Path, err)
+}
@@ -0,0 +1,261 @@
+package sdesc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func testItems(ids ...string) <-chan testItem {
+	ret := make(chan testItem)
+	go func() {
+		defer close(ret)
+		for _, id := range ids {
+			ret <- testItem{ID: id}
+		}
+	}()
+	return ret
+}
+
+func TestStreamFraming(t *testing.T) {
+	r := NewRouter()
+	r.MethodFunc(http.MethodGet, "/items", func(r *http.Request) (<-chan testItem, error) {
+		return testItems("a", "b"), nil
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		accept   string
+		wantCode int
+		wantType string
+		wantBody string
+	}{
+		{
+			name:     "default",
+			wantCode: http.StatusOK,
+			wantType: MediaTypeEventStream,
+			wantBody: "data: {\"id\":\"a\"}\n\ndata: {\"id\":\"b\"}\n\n",
+		},
+		{
+			name:     "event stream",
+			accept:   "text/event-stream",
+			wantCode: http.StatusOK,
+			wantType: MediaTypeEventStream,
+			wantBody: "data: {\"id\":\"a\"}\n\ndata: {\"id\":\"b\"}\n\n",
+		},
+		{
+			name:     "NDJSON",
+			accept:   "application/json;q=0.5, application/x-ndjson",
+			wantCode: http.StatusOK,
+			wantType: MediaTypeNDJSON,
+			wantBody: "{\"id\":\"a\"}\n{\"id\":\"b\"}\n",
+		},
+		{
+			name:     "unacceptable",
+			accept:   "application/json",
+			wantCode: http.StatusNotAcceptable,
+			wantType: MediaTypeJSON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/items", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rsp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rsp.Body.Close()
+			body, err := io.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rsp.StatusCode != tt.wantCode {
+				t.Errorf("got status %v, want %v", rsp.StatusCode, tt.wantCode)
+			}
+			if ct := rsp.Header.Get("Content-Type"); ct != tt.wantType {
+				t.Errorf("got Content-Type %q, want %q", ct, tt.wantType)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("got body %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestStreamDrainsAbandonedChannel checks that the handlers ignoring
+// the context don't block forever once the client is gone.
+func TestStreamDrainsAbandonedChannel(t *testing.T) {
+	// produce sends until it's stopped, then finishes the stream
+	produce := func(stop <-chan struct{}, done chan<- struct{}) <-chan testItem {
+		ret := make(chan testItem)
+		go func() {
+			defer close(done)
+			defer close(ret)
+			for {
+				select {
+				case <-stop:
+					ret <- testItem{ID: "last"}
+					return
+				default:
+				}
+				ret <- testItem{ID: "item"}
+			}
+		}()
+		return ret
+	}
+
+	tests := []struct {
+		name string
+		hdl  func(stop <-chan struct{}, done chan<- struct{}) http.Handler
+	}{
+		{"RPCHandler", func(stop <-chan struct{}, done chan<- struct{}) http.Handler {
+			r := NewRouter()
+			r.MethodFunc(http.MethodGet, "/items", func(r *http.Request) (<-chan testItem, error) {
+				return produce(stop, done), nil
+			})
+			return r
+		}},
+		{"WriteStream", func(stop <-chan struct{}, done chan<- struct{}) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				WriteStream(w, r, produce(stop, done))
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stop, done := make(chan struct{}), make(chan struct{})
+			srv := httptest.NewServer(tt.hdl(stop, done))
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/items", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rsp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := rsp.Body.Read(make([]byte, 1)); err != nil {
+				t.Fatal(err)
+			}
+			cancel()
+			rsp.Body.Close()
+			close(stop)
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("handler's sends are blocked after the client has gone")
+			}
+		})
+	}
+}
+
+func TestDoStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		hdl     http.HandlerFunc
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "complete",
+			hdl: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "{\"id\":\"a\"}\n\n{\"id\":\"b\"}\n")
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "malformed element",
+			hdl: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "{\"id\":\"a\"}\n{\"id\":\n{\"id\":\"c\"}\n")
+			},
+			want:    []string{"a"},
+			wantErr: true,
+		},
+		{
+			name: "dropped connection",
+			hdl: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "{\"id\":\"a\"}\n")
+				http.NewResponseController(w).Flush()
+				panic(http.ErrAbortHandler)
+			},
+			want:    []string{"a"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.hdl)
+			defer srv.Close()
+			c, err := NewClient(srv.URL, WithHTTPClient(srv.Client()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := DoStream[testItem](context.Background(), c, c.NewRequest(http.MethodGet, "/items"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for v := range s.C() {
+				got = append(got, v.ID)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("got elements %v, want %v", got, tt.want)
+			}
+			if (s.Err() != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", s.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDoStreamCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for {
+			if _, err := io.WriteString(w, "{\"id\":\"a\"}\n"); err != nil {
+				return
+			}
+			http.NewResponseController(w).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer srv.Close()
+	c, err := NewClient(srv.URL, WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := DoStream[testItem](ctx, c, c.NewRequest(http.MethodGet, "/items"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-s.C()
+	cancel()
+	for range s.C() {
+	}
+	if !errors.Is(s.Err(), context.Canceled) {
+		t.Errorf("got error %v, want %v", s.Err(), context.Canceled)
+	}
+}
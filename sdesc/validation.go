@@ -0,0 +1,44 @@
+package sdesc
+
+import (
+	"net/http"
+	"strings"
+)
+
+// FieldError describes a single invalid value of the request.
+type FieldError struct {
+	// Field is a Go name of the input struct's field.
+	// It is empty for the values rejected by a RequestValidator.
+	Field string `json:"field"`
+	// In is a location of the value: query, header, path, form or body.
+	In string `json:"in"`
+	// Name is a parameter name or a JSON field name.
+	Name string `json:"name"`
+	// Reason describes what's wrong with the value.
+	Reason string `json:"reason"`
+}
+
+// ValidationError is returned when the request does not fit
+// the handler's input, i.e. required values are missing
+// or values cannot be parsed.
+// It lists every offending field.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.In+" '"+f.Name+"': "+f.Reason)
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// StatusCode returns 400 Bad Request.
+func (e *ValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *ValidationError) add(f FieldError) {
+	e.Fields = append(e.Fields, f)
+}
@@ -0,0 +1,80 @@
+package sdesc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testDefaultsRequest struct {
+	Limit  int      `in:"query=limit;default=20"`
+	Tags   []string `in:"query=tag;default=new"`
+	Local  string   `in:"query=local;required"`
+	Region string   `in:"header=X-Region;required"`
+
+	Body *testJSONDirectives `in:"body=json"`
+}
+
+func TestRouterValidation(t *testing.T) {
+	var got testDefaultsRequest
+	r := NewRouter()
+	r.MethodFunc(http.MethodPost, "/items", func(r *http.Request, in testDefaultsRequest) error {
+		got = in
+		return nil
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/items?local=here", strings.NewReader(`{"RequiredOnly":"set"}`))
+		req.Header.Set("X-Region", "eu")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %v, want 200; body %s", w.Code, w.Body)
+		}
+
+		want := testDefaultsRequest{
+			Limit:  20,
+			Tags:   []string{"new"},
+			Local:  "here",
+			Region: "eu",
+			Body:   &testJSONDirectives{WithDefault: "1234", RequiredOnly: "set"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("missing values", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/items?limit=many", strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("got status %v, want 400; body %s", w.Code, w.Body)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != MediaTypeJSON {
+			t.Errorf("got Content-Type %q", ct)
+		}
+
+		var rsp errorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(rsp.Error, "invalid request: ") {
+			t.Errorf("got error %q", rsp.Error)
+		}
+		var fields []string
+		for _, f := range rsp.Fields {
+			if f.Reason == "" {
+				t.Errorf("field %+v has no reason", f)
+			}
+			fields = append(fields, f.In+" "+f.Name)
+		}
+		want := []string{"query limit", "query local", "header X-Region", "body RequiredOnly"}
+		if !reflect.DeepEqual(fields, want) {
+			t.Errorf("got fields %q, want %q", fields, want)
+		}
+	})
+}
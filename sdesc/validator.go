@@ -0,0 +1,210 @@
+package sdesc
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/internal/spec"
+)
+
+// RequestValidator is a middleware that checks the requests against
+// the operations of the OpenAPI documents generated by pontoongen:
+// their parameters, required values, types and body schemas.
+// Invalid requests are rejected by a *ValidationError
+// before they reach the handler, see WriteError.
+//
+// It finds the operation by the route's RouteInfo, so it should be
+// one of the Router's middlewares:
+//
+//	v, err := sdesc.NewRequestValidator(svc)
+//	r := sdesc.NewRouter(sdesc.WithGlobalMiddlewares(v.Middleware))
+//	r.Register(svc)
+//
+// Bodies of media types that openapi3filter cannot decode,
+// i.e. the ones of custom Codecs, aren't checked.
+type RequestValidator struct {
+	// routes are keyed by the method and the path pattern.
+	routes map[string]*routers.Route
+}
+
+// mediaTypeForm is a media type of the forms without files.
+const mediaTypeForm = "application/x-www-form-urlencoded"
+
+func init() {
+	// openapi3filter decodes the fields missing from the form
+	// as nulls, so the optional ones are reported as invalid
+	decode := openapi3filter.RegisteredBodyDecoder(mediaTypeForm)
+	openapi3filter.RegisterBodyDecoder(mediaTypeForm, func(body io.Reader, h http.Header, s *openapi3.SchemaRef, fn openapi3filter.EncodingFn) (interface{}, error) {
+		v, err := decode(body, h, s, fn)
+		if obj, ok := v.(map[string]interface{}); ok {
+			for k, fv := range obj {
+				if fv == nil {
+					delete(obj, k)
+				}
+			}
+		}
+		return v, err
+	})
+}
+
+// NewRequestValidator loads the OpenAPI documents of the Services.
+// Every Service should implement OpenAPIProvider.
+func NewRequestValidator(svcs ...Service) (*RequestValidator, error) {
+	routes, err := loadRoutes(svcs)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestValidator{routes: routes}, nil
+}
+
+// loadRoutes loads the operations of the Services' OpenAPI documents
+// keyed by their methods and path patterns.
+func loadRoutes(svcs []Service) (map[string]*routers.Route, error) {
+	ret := map[string]*routers.Route{}
+	for _, s := range svcs {
+		p, ok := s.(OpenAPIProvider)
+		if !ok {
+			return nil, errors.Errorf("service %T has no OpenAPI() method - did you run pontoongen?", s)
+		}
+		doc, err := openapi3.NewLoader().LoadFromData([]byte(p.OpenAPI()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading OpenAPI document of %T", s)
+		}
+
+		for path, item := range doc.Paths {
+			for method, op := range item.Operations() {
+				key := method + " " + path
+				if _, ok := ret[key]; ok {
+					return nil, errors.Errorf("'%v' is described by several services", key)
+				}
+				ret[key] = &routers.Route{
+					Spec:      doc,
+					Path:      path,
+					PathItem:  item,
+					Method:    method,
+					Operation: op,
+				}
+			}
+		}
+	}
+	return ret, nil
+}
+
+// Middleware rejects invalid requests of the described routes;
+// requests of other routes are passed as is.
+func (v *RequestValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ri, ok := RouteFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		route, ok := v.routes[ri.Method+" "+ri.Pattern]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		err := v.validate(r, route)
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *RequestValidator) validate(r *http.Request, route *routers.Route) error {
+	in := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: map[string]string{},
+		Route:      route,
+		Options: &openapi3filter.Options{
+			MultiError: true,
+			// the Router's Authenticator checks the credentials
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+	for _, params := range []openapi3.Parameters{route.PathItem.Parameters, route.Operation.Parameters} {
+		for _, p := range params {
+			if p.Value != nil && p.Value.In == openapi3.ParameterInPath {
+				in.PathParams[p.Value.Name] = r.PathValue(p.Value.Name)
+			}
+		}
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, _ := mime.ParseMediaType(ct)
+		in.Options.ExcludeRequestBody = openapi3filter.RegisteredBodyDecoder(mt) == nil
+	}
+
+	err := openapi3filter.ValidateRequest(r.Context(), in)
+	if err == nil {
+		return nil
+	}
+	verr := &ValidationError{}
+	addRequestErrors(verr, err)
+	if len(verr.Fields) == 0 {
+		return WithStatus(err, http.StatusBadRequest)
+	}
+	return verr
+}
+
+// addRequestErrors lists the errors of openapi3filter as FieldErrors.
+func addRequestErrors(verr *ValidationError, err error) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, err := range e {
+			addRequestErrors(verr, err)
+		}
+	case *openapi3filter.RequestError:
+		switch {
+		case e.Parameter != nil:
+			addValueErrors(verr, e.Parameter.In, e.Parameter.Name, e)
+		case e.RequestBody != nil:
+			addValueErrors(verr, spec.LocBody, "json", e)
+		}
+	}
+}
+
+// addValueErrors lists the errors of the parameter or the body;
+// errors of the body's fields are named by their JSON paths,
+// i.e. "items.0.id".
+func addValueErrors(verr *ValidationError, loc, name string, e *openapi3filter.RequestError) {
+	var errs openapi3.MultiError
+	switch ee := e.Err.(type) {
+	case openapi3.MultiError:
+		errs = ee
+	case nil:
+		verr.add(FieldError{In: loc, Name: name, Reason: e.Reason})
+		return
+	default:
+		errs = openapi3.MultiError{ee}
+	}
+
+	for _, err := range errs {
+		f := FieldError{In: loc, Name: name, Reason: err.Error()}
+		var se *openapi3.SchemaError
+		var pe *openapi3filter.ParseError
+		switch {
+		case errors.Is(err, openapi3filter.ErrInvalidRequired):
+			f.Reason = "value is required"
+		case errors.As(err, &se):
+			f.Reason = se.Reason
+			if se.SchemaField == "required" {
+				f.Reason = "value is required"
+			}
+			if ptr := se.JSONPointer(); loc == spec.LocBody && len(ptr) > 0 {
+				f.Name = strings.Join(ptr, ".")
+			}
+		case errors.As(err, &pe) && pe.Reason != "":
+			f.Reason = "cannot parse value: " + pe.Reason
+		}
+		verr.add(f)
+	}
+}
@@ -0,0 +1,252 @@
+package sdesc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testSpecService is a testService described by the OpenAPI document.
+type testSpecService struct {
+	testService
+	spec string
+}
+
+func (s testSpecService) OpenAPI() string { return s.spec }
+
+const testItemsSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Items", "version": "1"},
+  "paths": {
+    "/items/{id}": {
+      "get": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}}
+        ],
+        "responses": {
+          "200": {
+            "description": "success",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}
+          },
+          "404": {
+            "description": "not found",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+          }
+        }
+      }
+    },
+    "/items": {
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {
+            "type": "object",
+            "required": ["name"],
+            "properties": {
+              "name": {"type": "string", "minLength": 1},
+              "count": {"type": "integer", "minimum": 0}
+            }
+          }}}
+        },
+        "responses": {
+          "200": {
+            "description": "success",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Item": {
+        "type": "object",
+        "required": ["id"],
+        "properties": {"id": {"type": "string"}}
+      },
+      "Error": {
+        "type": "object",
+        "properties": {
+          "error": {"type": "string"},
+          "fields": {"type": "array", "items": {"type": "object"}}
+        }
+      }
+    }
+  }
+}`
+
+type testCreateRequest struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// testItemsService serves testItemsSpec; GET /items/{id}
+// responds with the item of the ID, breaking the contract on purpose.
+func testItemsService(opts ...ServiceOption) testSpecService {
+	return testSpecService{
+		spec: testItemsSpec,
+		testService: testService{
+			opts: opts,
+			fn: func(r HTTPRouter) {
+				r.MethodFunc(http.MethodGet, "/items/{id}", func(r *http.Request, in testGetRequest) (interface{}, error) {
+					switch in.ID {
+					case "missing":
+						return nil, NotFound("no such item")
+					case "conflict":
+						return nil, Conflict("changed concurrently")
+					case "extra":
+						return map[string]interface{}{"id": "extra", "extra": 1}, nil
+					case "number":
+						return map[string]interface{}{"id": 1}, nil
+					}
+					return &testItem{ID: in.ID}, nil
+				})
+				r.MethodFunc(http.MethodPost, "/items", func(r *http.Request, in testCreateRequest) (*testItem, error) {
+					return &testItem{ID: in.Name}, nil
+				})
+			},
+		},
+	}
+}
+
+func TestRequestValidator(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		problems   bool
+		wantCode   int
+		wantFields []FieldError
+	}{
+		{
+			name:     "valid query",
+			method:   http.MethodGet,
+			target:   "/items/1?limit=10",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "out of bounds",
+			method:   http.MethodGet,
+			target:   "/items/1?limit=0",
+			wantCode: http.StatusBadRequest,
+			wantFields: []FieldError{
+				{In: "query", Name: "limit", Reason: "number must be at least 1"},
+			},
+		},
+		{
+			name:     "malformed",
+			method:   http.MethodGet,
+			target:   "/items/1?limit=many",
+			wantCode: http.StatusBadRequest,
+			wantFields: []FieldError{
+				{In: "query", Name: "limit", Reason: "cannot parse value: an invalid integer"},
+			},
+		},
+		{
+			name:     "valid body",
+			method:   http.MethodPost,
+			target:   "/items",
+			body:     `{"name":"posted","count":1}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid body",
+			method:   http.MethodPost,
+			target:   "/items",
+			body:     `{"count":-1}`,
+			wantCode: http.StatusBadRequest,
+			wantFields: []FieldError{
+				{In: "body", Name: "count", Reason: "number must be at least 0"},
+				{In: "body", Name: "name", Reason: "value is required"},
+			},
+		},
+		{
+			name:     "missing body",
+			method:   http.MethodPost,
+			target:   "/items",
+			wantCode: http.StatusBadRequest,
+			wantFields: []FieldError{
+				{In: "body", Name: "json", Reason: "value is required"},
+			},
+		},
+		{
+			name:     "problem details",
+			method:   http.MethodGet,
+			target:   "/items/1?limit=101",
+			problems: true,
+			wantCode: http.StatusBadRequest,
+			wantFields: []FieldError{
+				{In: "query", Name: "limit", Reason: "number must be most 100"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []ServiceOption
+			if tt.problems {
+				opts = append(opts, WithProblemDetails())
+			}
+			svc := testItemsService(opts...)
+			v, err := NewRequestValidator(svc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewRouter(WithGlobalMiddlewares(v.Middleware))
+			r.Register(svc)
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", MediaTypeJSON)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("got status %v, want %v; body %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantFields == nil {
+				return
+			}
+
+			var got []FieldError
+			if tt.problems {
+				var pd ProblemDetails
+				if err := json.Unmarshal(w.Body.Bytes(), &pd); err != nil {
+					t.Fatal(err)
+				}
+				if pd.Status != tt.wantCode || pd.Title != "Bad Request" {
+					t.Errorf("got problem %+v", pd)
+				}
+				got = pd.InvalidParams
+			} else {
+				var rsp errorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(rsp.Error, "invalid request: ") {
+					t.Errorf("got error %q", rsp.Error)
+				}
+				got = rsp.Fields
+			}
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("got fields %+v, want %+v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestNewRequestValidatorNeedsSpec(t *testing.T) {
+	_, err := NewRequestValidator(testService{})
+	if err == nil {
+		t.Error("got no error for a Service without OpenAPI()")
+	}
+	_, err = NewRequestValidator(testItemsService(), testItemsService())
+	if err == nil {
+		t.Error("got no error for the routes described twice")
+	}
+}
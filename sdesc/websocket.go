@@ -0,0 +1,195 @@
+package sdesc
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketHandler is a function of type
+// func(context.Context,<input type>,<-chan <client message>,chan<- <server message>) error
+// or
+// func(context.Context,<-chan <client message>,chan<- <server message>) error.
+//
+// The input is bound from the upgrade request like RPCHandler's input.
+// Every message is a JSON text frame. The client's messages
+// are received from the first channel, which is closed once the client
+// stops sending; the handler's messages are sent to the second one.
+// The context is done when the client goes away.
+// The connection is closed once the handler returns;
+// its error is logged.
+//
+// Use HandleWebSocket to check the signature at compile time.
+type WebSocketHandler interface{}
+
+// HandleWebSocket registers a WebSocket handler of the request's input In
+// that receives the client's messages C and sends the messages S;
+// see WebSocketHandler.
+//
+//	sdesc.HandleWebSocket(mux, "/v1/chat/{room}", h.chat)
+func HandleWebSocket[In, C, S any](r HTTPRouter, pattern string, fn func(context.Context, In, <-chan C, chan<- S) error, opts ...RouteOption) {
+	r.WebSocket(pattern, fn, opts...)
+}
+
+// ServeWebSocket upgrades the connection and runs the handler
+// until it returns; it's called by the code generated by pontoongen.
+func ServeWebSocket[C, S any](w http.ResponseWriter, r *http.Request, fn func(context.Context, <-chan C, chan<- S) error) {
+	h := &rpcHandler{}
+	if n, ok := negotiationFromContext(r.Context()); ok {
+		h = n.h
+	}
+	recv, send := make(chan C), make(chan S)
+	h.serveSocket(w, r, reflect.ValueOf(recv), reflect.ValueOf(send), func(ctx context.Context) error {
+		return fn(ctx, recv, send)
+	})
+}
+
+// isWebSocketUpgrade is true if the client asks for a WebSocket.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// serveSocket upgrades the connection; the handler is run
+// with the channels recv and send of the client's and server's messages.
+func (h *rpcHandler) serveSocket(w http.ResponseWriter, r *http.Request, recv, send reflect.Value, run func(context.Context) error) {
+	hw, ok := hijacker(w)
+	if !ok {
+		h.writeError(w, r, Internal("connection cannot be upgraded"))
+		return
+	}
+	srv := websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			return checkOrigin(r, h.origins)
+		},
+		Handler: func(conn *websocket.Conn) {
+			runSocket(r, conn, recv, send, func(ctx context.Context) error {
+				return run(withCall(ctx, r, w))
+			})
+		},
+	}
+	srv.ServeHTTP(hw, r)
+}
+
+// hijacker unwraps the ResponseWriter until it can be hijacked.
+func hijacker(w http.ResponseWriter) (http.ResponseWriter, bool) {
+	for {
+		if _, ok := w.(http.Hijacker); ok {
+			return w, true
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil, false
+		}
+		w = u.Unwrap()
+	}
+}
+
+// checkOrigin allows the clients without an Origin, like non-browser ones,
+// the same origin and the origins allowed by WithOrigins.
+func checkOrigin(r *http.Request, allowed []string) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if a == "*" || strings.EqualFold(a, origin) {
+			return nil
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return errors.Wrap(err, "bad Origin")
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return nil
+	}
+	return errors.Errorf("origin '%v' is not allowed", origin)
+}
+
+// runSocket pumps the messages between the connection
+// and the handler's channels until the handler returns.
+func runSocket(r *http.Request, conn *websocket.Conn, recv, send reflect.Value, run func(context.Context) error) {
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	go func() {
+		// the client has gone away once it stops sending
+		defer cancel()
+		defer recv.Close()
+		done := reflect.ValueOf(ctx.Done())
+		for {
+			msg := reflect.New(recv.Type().Elem())
+			err := websocket.JSON.Receive(conn, msg.Interface())
+			if err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					log.Printf("sdesc: websocket %v: receiving: %v", r.URL.Path, err)
+				}
+				return
+			}
+			chosen, _, _ := reflect.Select([]reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: done},
+				{Dir: reflect.SelectSend, Chan: recv, Send: msg.Elem()},
+			})
+			if chosen == 0 {
+				return
+			}
+		}
+	}()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- runRecovered(ctx, r, run)
+	}()
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(errc)},
+		{Dir: reflect.SelectRecv, Chan: send},
+	}
+	broken := false
+	for {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 {
+			err, _ := v.Interface().(error)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("sdesc: websocket %v: %v", r.URL.Path, err)
+			}
+			return
+		}
+		if !ok {
+			// the handler has closed its channel
+			cases[1].Chan = reflect.Zero(send.Type())
+			continue
+		}
+		if broken {
+			// drop the messages so the handler doesn't block
+			continue
+		}
+		err := websocket.JSON.Send(conn, v.Interface())
+		if err != nil {
+			broken = true
+			cancel()
+		}
+	}
+}
+
+func runRecovered(ctx context.Context, r *http.Request, run func(context.Context) error) (err error) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+		log.Printf("sdesc: panic serving websocket %v: %v\n%s", r.URL.Path, p, debug.Stack())
+		err = Internal("internal error")
+	}()
+	return run(ctx)
+}
@@ -0,0 +1,193 @@
+package sdesc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+type testRoomRequest struct {
+	Room string `in:"path=room;required"`
+}
+
+type testMessage struct {
+	Room string `json:"room"`
+	Text string `json:"text"`
+}
+
+// newSocketServer serves the WebSocket handler at /ws/{room}.
+func newSocketServer(t *testing.T, hdl WebSocketHandler, opts ...RouteOption) *httptest.Server {
+	t.Helper()
+	r := NewRouter()
+	r.WebSocket("/ws/{room}", hdl, opts...)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dialSocket(srv *httptest.Server, origin string) (*websocket.Conn, error) {
+	if origin == "" {
+		origin = srv.URL
+	}
+	return websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/lobby", "", origin)
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	srv := newSocketServer(t, func(ctx context.Context, req testRoomRequest, recv <-chan testMessage, send chan<- testMessage) error {
+		for m := range recv {
+			m.Room = req.Room
+			select {
+			case send <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	conn, err := dialSocket(srv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, text := range []string{"hello", "again"} {
+		if err := websocket.JSON.Send(conn, testMessage{Text: text}); err != nil {
+			t.Fatal(err)
+		}
+		var got testMessage
+		if err := websocket.JSON.Receive(conn, &got); err != nil {
+			t.Fatal(err)
+		}
+		if want := (testMessage{Room: "lobby", Text: text}); got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	hdl := func(ctx context.Context, recv <-chan testMessage, send chan<- testMessage) error {
+		<-ctx.Done()
+		return nil
+	}
+	tests := []struct {
+		name    string
+		opts    []RouteOption
+		origin  string
+		wantErr bool
+	}{
+		{name: "same origin"},
+		{name: "foreign origin", origin: "https://evil.example", wantErr: true},
+		{name: "allowed origin", opts: []RouteOption{WithOrigins("https://example.com")}, origin: "https://example.com"},
+		{name: "any origin", opts: []RouteOption{WithOrigins("*")}, origin: "https://evil.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSocketServer(t, hdl, tt.opts...)
+			conn, err := dialSocket(srv, tt.origin)
+			if err == nil {
+				conn.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebSocketRequiresUpgrade(t *testing.T) {
+	srv := newSocketServer(t, func(ctx context.Context, recv <-chan testMessage, send chan<- testMessage) error {
+		t.Error("handler is called without an upgrade")
+		return nil
+	})
+
+	rsp, err := srv.Client().Get(srv.URL + "/ws/lobby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("got status %v, want %v", rsp.StatusCode, http.StatusUpgradeRequired)
+	}
+	if u := rsp.Header.Get("Upgrade"); u != "websocket" {
+		t.Errorf("got Upgrade %q, want websocket", u)
+	}
+}
+
+func TestWebSocketClientCloses(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	srv := newSocketServer(t, func(ctx context.Context, recv <-chan testMessage, send chan<- testMessage) error {
+		close(started)
+		<-ctx.Done()
+		done <- ctx.Err()
+		return nil
+	})
+
+	conn, err := dialSocket(srv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	conn.Close()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("got context error %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler's context isn't done after the client has closed")
+	}
+}
+
+func TestWebSocketHandlerPanics(t *testing.T) {
+	srv := newSocketServer(t, func(ctx context.Context, recv <-chan testMessage, send chan<- testMessage) error {
+		<-recv
+		panic("boom")
+	})
+
+	conn, err := dialSocket(srv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := websocket.JSON.Send(conn, testMessage{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the connection is closed once the handler is recovered
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var m testMessage
+	if err := websocket.JSON.Receive(conn, &m); err == nil {
+		t.Errorf("got message %+v, want the connection closed", m)
+	}
+}
+
+func TestWebSocketRejectsBadSignatures(t *testing.T) {
+	tests := []struct {
+		name string
+		hdl  WebSocketHandler
+	}{
+		{"no context", func(recv <-chan testMessage, send chan<- testMessage) error { return nil }},
+		{"no send channel", func(ctx context.Context, recv <-chan testMessage) error { return nil }},
+		{"result", func(ctx context.Context, recv <-chan testMessage, send chan<- testMessage) (*testMessage, error) {
+			return nil, nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("WebSocket didn't panic")
+				}
+			}()
+			NewRouter().WebSocket("/ws", tt.hdl)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package test
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/utrack/pontoon/sdesc"
@@ -9,6 +12,7 @@ import (
 )
 
 // Handler struct comment
+// pontoon:response 500
 type Handler struct {
 }
 
@@ -19,6 +23,12 @@ type iterateRequest struct {
 
 	Foo int64 `in:"query=foo"`
 
+	// Limit is a maximum count of products on a page.
+	Limit int `in:"query=limit;default=20" validate:"min=1,max=100"`
+
+	// InStock hides the products out of stock.
+	InStock bool `in:"query=in_stock;default=true"`
+
 	// Local describes Some Stuff(tm). Required field.
 	Local string `in:"query=local;required"`
 
@@ -46,8 +56,50 @@ type nonAnnotJSON struct {
 	//TODO *nonAnnotJSON blows up the stack
 	nonAnnotJSON2
 	Foo string `json:"foo"`
+
+	Email string `json:"email" validate:"required,email"`
+
+	Tags []string `json:"tags" validate:"max=10,unique,dive,oneof=new sale 'top rated'"`
+
+	Status ProductStatus `json:"status"`
+
+	Priority *Priority `json:"priority,omitempty"`
+
+	// TTL is a duration of another module, its constants aren't an enum.
+	TTL time.Duration `json:"ttl"`
+
+	// Quantity has a single constant, it isn't an enum.
+	Quantity Quantity `json:"quantity"`
 }
 
+// ProductStatus is a state of a product in the catalog.
+type ProductStatus string
+
+const (
+	// ProductStatusActive is a product on sale.
+	ProductStatusActive ProductStatus = "active"
+	// ProductStatusArchived is a product hidden from the catalog.
+	ProductStatusArchived ProductStatus = "archived"
+	ProductStatusDraft    ProductStatus = "draft" // not published yet
+
+	// productStatusDeleted is never shown to the clients.
+	productStatusDeleted ProductStatus = "deleted"
+)
+
+// Quantity is a number of products in the stock.
+type Quantity int
+
+// DefaultQuantity is the quantity of new products.
+const DefaultQuantity Quantity = 20
+
+// Priority is an order of products' processing.
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityHigh
+)
+
 type nonAnnotJSON2 struct {
 	Bar string `json:"bar"`
 }
@@ -65,12 +117,27 @@ type jsonWithArrayOfStructs struct {
 
 type dummyStruct struct {
 	DummyField string
+
+	// ID identifies the struct.
+	ID       string   `json:"id" validate:"required,uuid4"`
+	Code     string   `json:"code" validate:"min=6,max=8,startswith=P-"`
+	Homepage string   `json:"homepage" validate:"omitempty,url"`
+	Score    float64  `json:"score" validate:"gt=0,lt=1"`
+	Count    int      `json:"count" validate:"gte=10"`
+	Labels   []string `json:"labels" validate:"min=2,dive,alpha"`
+}
+
+// conflictError is returned when the resource was changed concurrently.
+type conflictError struct {
+	Error   string `json:"error"`
+	Version int    `json:"version"`
 }
 
 var _ sdesc.Service = &Handler{}
 
 // IterateProducts comment
 // Includes imported package
+// pontoon:response 404
 func (h Handler) iterateProducts(r *http.Request, req iterateRequest) (*test2.IterateResponse, error) {
 	return nil, errors.New("NIH")
 }
@@ -80,6 +147,9 @@ func (h Handler) nonAnnotIn(r *http.Request, req nonAnnotJSON) (interface{}, err
 }
 
 func (h Handler) ifaceReturn(r *http.Request, req iterateRequest) (interface{}, error) {
+	if req.Foo == 0 {
+		return nil, errGone
+	}
 	return nil, errors.New("NIH")
 }
 
@@ -88,10 +158,16 @@ func (h Handler) ifaceReturnAny(r *http.Request, req iterateRequest) (any, error
 }
 
 func (h Handler) zeroReturn(r *http.Request, req iterateRequest) error {
-	return errors.New("NIH")
+	if req.Local == "" {
+		return fmt.Errorf("zero: %w", errGone)
+	}
+	return sdesc.NotFound("NIH")
 }
 
 func (h Handler) sliceReturn(r *http.Request, req iterateRequest) ([]test2.IterateResponse, error) {
+	if err := h.checkAccess(r); err != nil {
+		return nil, err
+	}
 	return nil, errors.New("NIH")
 }
 
@@ -100,13 +176,107 @@ func (h Handler) sliceInObjReturn(r *http.Request) (*jsonWithArrayOfStructs, err
 }
 
 func (h Handler) mapReturn(r *http.Request, req iterateRequest) (map[string]test2.IterateResponse, error) {
-	return nil, errors.New("NIH")
+	return nil, &quotaError{}
+}
+
+// streamReturn sends a response per second until the client goes away.
+func (h Handler) streamReturn(r *http.Request, req iterateRequest) (<-chan test2.IterateResponse, error) {
+	ret := make(chan test2.IterateResponse)
+	go func() {
+		defer close(ret)
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-t.C:
+			}
+			select {
+			case <-r.Context().Done():
+				return
+			case ret <- test2.IterateResponse{}:
+			}
+		}
+	}()
+	return ret, nil
+}
+
+type echoRequest struct {
+	Room string `in:"path=room;required"`
+}
+
+// echoMessage is a message of the echo WebSocket.
+type echoMessage struct {
+	Room string `json:"room"`
+	Text string `json:"text"`
+}
+
+// echo sends every client's message back.
+func (h Handler) echo(ctx context.Context, req echoRequest, recv <-chan echoMessage, send chan<- echoMessage) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-recv:
+			if !ok {
+				return nil
+			}
+			m.Room = req.Room
+			select {
+			case send <- m:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// ticks sends a response per second, ignoring the client's messages.
+func (h Handler) ticks(ctx context.Context, recv <-chan string, send chan<- test2.IterateResponse) error {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-recv:
+		case <-t.C:
+			select {
+			case send <- test2.IterateResponse{}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
 }
 
+// jsonWithDirs responds with a custom error body.
+//
+//pontoon:response 409 conflictError
 func (h Handler) jsonWithDirs(r *http.Request, req jsonWithDirectives) error {
 	return errors.New("NIH")
 }
 
+var errGone = sdesc.Errorf(http.StatusGone, "%v is gone", "it")
+
+func (h Handler) checkAccess(r *http.Request) error {
+	if r.Header.Get("Authorization") == "" {
+		return sdesc.Forbidden("no credentials")
+	}
+	return nil
+}
+
+type quotaError struct{}
+
+func (quotaError) Error() string {
+	return "quota exceeded"
+}
+
+func (quotaError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
 func (h Handler) ServiceOptions() []sdesc.ServiceOption {
 	return nil
 }
@@ -7,10 +7,141 @@ func (s Handler) OpenAPI() string {
 	return `{
     "components": {
       "schemas": {
+        "sdesc.ErrorResponse": {
+          "description": "Default body of a failed call",
+          "properties": {
+            "error": {
+              "description": "Error message",
+              "type": "string"
+            }
+          },
+          "required": [
+            "error"
+          ],
+          "type": "object"
+        },
+        "sdesc.FieldError": {
+          "description": "Single invalid value of the request",
+          "properties": {
+            "field": {
+              "description": "Go name of the input struct's field",
+              "type": "string"
+            },
+            "in": {
+              "description": "Location of the value",
+              "enum": [
+                "query",
+                "header",
+                "path",
+                "form",
+                "body"
+              ],
+              "type": "string"
+            },
+            "name": {
+              "description": "Parameter name or JSON field name",
+              "type": "string"
+            },
+            "reason": {
+              "description": "What's wrong with the value",
+              "type": "string"
+            }
+          },
+          "required": [
+            "field",
+            "in",
+            "name",
+            "reason"
+          ],
+          "type": "object"
+        },
+        "sdesc.ValidationError": {
+          "description": "Request does not fit the handler's input",
+          "properties": {
+            "error": {
+              "type": "string"
+            },
+            "fields": {
+              "items": {
+                "$ref": "#/components/schemas/sdesc.FieldError"
+              },
+              "type": "array"
+            }
+          },
+          "required": [
+            "error"
+          ],
+          "type": "object"
+        },
+        "test.conflictError": {
+          "description": "Returned when the resource was changed concurrently.",
+          "properties": {
+            "error": {
+              "type": "string"
+            },
+            "version": {
+              "format": "int64",
+              "type": "integer"
+            }
+          },
+          "type": "object"
+        },
         "test.dummyStruct": {
           "properties": {
             "DummyField": {
               "type": "string"
+            },
+            "code": {
+              "maxLength": 8,
+              "minLength": 6,
+              "pattern": "^P-",
+              "type": "string"
+            },
+            "count": {
+              "format": "int64",
+              "minimum": 10,
+              "type": "integer"
+            },
+            "homepage": {
+              "format": "uri",
+              "type": "string"
+            },
+            "id": {
+              "description": "Identifies the struct.",
+              "format": "uuid",
+              "type": "string"
+            },
+            "labels": {
+              "items": {
+                "pattern": "^[a-zA-Z]+$",
+                "type": "string"
+              },
+              "minItems": 2,
+              "nullable": true,
+              "type": "array"
+            },
+            "score": {
+              "exclusiveMaximum": true,
+              "exclusiveMinimum": true,
+              "format": "double",
+              "maximum": 1,
+              "minimum": 0,
+              "type": "number"
+            }
+          },
+          "required": [
+            "id"
+          ],
+          "type": "object"
+        },
+        "test.echoMessage": {
+          "description": "A message of the echo WebSocket.",
+          "properties": {
+            "room": {
+              "type": "string"
+            },
+            "text": {
+              "type": "string"
             }
           },
           "type": "object"
@@ -77,7 +208,6 @@ func (s Handler) OpenAPI() string {
             }
           },
           "required": [
-            "with_default",
             "RequiredOnly"
           ],
           "type": "object"
@@ -93,10 +223,63 @@ func (s Handler) OpenAPI() string {
           ],
           "description": "Represents a 'raw' JSON struct without annotations with an embed no-annotated one",
           "properties": {
+            "email": {
+              "format": "email",
+              "type": "string"
+            },
             "foo": {
               "type": "string"
+            },
+            "priority": {
+              "enum": [
+                1,
+                2
+              ],
+              "format": "int64",
+              "nullable": true,
+              "type": "integer"
+            },
+            "quantity": {
+              "description": "Has a single constant, it isn't an enum.",
+              "format": "int64",
+              "type": "integer"
+            },
+            "status": {
+              "enum": [
+                "active",
+                "archived",
+                "draft"
+              ],
+              "type": "string",
+              "x-enum-descriptions": [
+                "A product on sale.",
+                "A product hidden from the catalog.",
+                "Not published yet"
+              ]
+            },
+            "tags": {
+              "items": {
+                "enum": [
+                  "new",
+                  "sale",
+                  "top rated"
+                ],
+                "type": "string"
+              },
+              "maxItems": 10,
+              "nullable": true,
+              "type": "array",
+              "uniqueItems": true
+            },
+            "ttl": {
+              "description": "A duration of another module, its constants aren't an enum.",
+              "format": "int64",
+              "type": "integer"
             }
           },
+          "required": [
+            "email"
+          ],
           "type": "object"
         },
         "test.nonAnnotJSON2": {
@@ -145,6 +328,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -158,7 +364,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -192,6 +397,36 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "404": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Not Found"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -222,6 +457,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -235,7 +493,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -269,6 +526,36 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "404": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Not Found"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -301,6 +588,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -314,7 +624,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -348,6 +657,36 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "404": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Not Found"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -375,11 +714,33 @@ func (s Handler) OpenAPI() string {
             "200": {
               "content": {
                 "application/json": {
-                  "schema": {}
+                  "schema": {
+                    "nullable": true
+                  }
                 }
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -391,7 +752,8 @@ func (s Handler) OpenAPI() string {
       },
       "/v1/test/request/jsonWithDirective": {
         "get": {
-          "operationId": "v1_test_request_jsonwithdirective_get",
+          "description": "Responds with a custom error body.",
+          "operationId": "getJSONWithDirectives",
           "requestBody": {
             "content": {
               "application/json": {
@@ -409,12 +771,43 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "409": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test.conflictError"
+                  }
+                }
+              },
+              "description": "Conflict"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
           },
           "tags": [
-            "test.Handler"
+            "test.Handler",
+            "directives"
           ]
         }
       },
@@ -439,6 +832,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -452,7 +868,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -479,11 +894,43 @@ func (s Handler) OpenAPI() string {
             "200": {
               "content": {
                 "application/json": {
-                  "schema": {}
+                  "schema": {
+                    "nullable": true
+                  }
                 }
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "410": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Gone"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -495,6 +942,7 @@ func (s Handler) OpenAPI() string {
       },
       "/v1/test/return/interface-any": {
         "get": {
+          "deprecated": true,
           "operationId": "v1_test_return_interface-any_get",
           "parameters": [
             {
@@ -514,6 +962,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -527,7 +998,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -554,11 +1024,33 @@ func (s Handler) OpenAPI() string {
             "200": {
               "content": {
                 "application/json": {
-                  "schema": {}
+                  "schema": {
+                    "nullable": true
+                  }
                 }
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -589,6 +1081,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -602,7 +1117,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -639,6 +1153,36 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "429": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Too Many Requests"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -669,6 +1213,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -682,7 +1249,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -712,6 +1278,46 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "404": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Not Found"
+            },
+            "410": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Gone"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -742,6 +1348,29 @@ func (s Handler) OpenAPI() string {
                 "type": "integer"
               }
             },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
             {
               "description": "Describes Some Stuff(tm). Required field.",
               "in": "query",
@@ -755,7 +1384,6 @@ func (s Handler) OpenAPI() string {
             {
               "in": "query",
               "name": "local_default",
-              "required": true,
               "schema": {
                 "default": "foobarbaz",
                 "type": "string"
@@ -793,6 +1421,46 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "403": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Forbidden"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
+            "504": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Gateway Timeout"
+            },
             "default": {
               "description": ""
             }
@@ -816,6 +1484,16 @@ func (s Handler) OpenAPI() string {
               },
               "description": "success"
             },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
             "default": {
               "description": ""
             }
@@ -824,6 +1502,251 @@ func (s Handler) OpenAPI() string {
             "test.Handler"
           ]
         }
+      },
+      "/v1/test/return/stream": {
+        "get": {
+          "description": "Sends a response per second until the client goes away.",
+          "operationId": "v1_test_return_stream_get",
+          "parameters": [
+            {
+              "description": "A token for the next page.\nPass an empty page_token if you want to request the first page,\nand use a token from the response as page_token to get the next page.",
+              "in": "query",
+              "name": "page_token",
+              "schema": {
+                "description": "A token for the next page.\nPass an empty PageToken if you want to request the first page,\nand use a token from the response as PageToken to get the next page.",
+                "type": "string"
+              }
+            },
+            {
+              "in": "query",
+              "name": "foo",
+              "schema": {
+                "format": "int64",
+                "type": "integer"
+              }
+            },
+            {
+              "description": "A maximum count of products on a page.",
+              "in": "query",
+              "name": "limit",
+              "schema": {
+                "default": 20,
+                "description": "A maximum count of products on a page.",
+                "format": "int64",
+                "maximum": 100,
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Hides the products out of stock.",
+              "in": "query",
+              "name": "in_stock",
+              "schema": {
+                "default": true,
+                "description": "Hides the products out of stock.",
+                "type": "boolean"
+              }
+            },
+            {
+              "description": "Describes Some Stuff(tm). Required field.",
+              "in": "query",
+              "name": "local",
+              "required": true,
+              "schema": {
+                "description": "Describes Some Stuff(tm). Required field.",
+                "type": "string"
+              }
+            },
+            {
+              "in": "query",
+              "name": "local_default",
+              "schema": {
+                "default": "foobarbaz",
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "anyOf": [
+                    {
+                      "type": "null"
+                    },
+                    {
+                      "$ref": "#/components/schemas/test.iterateRequest"
+                    }
+                  ]
+                }
+              }
+            }
+          },
+          "responses": {
+            "200": {
+              "content": {
+                "application/x-ndjson": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test2.IterateResponse"
+                  }
+                },
+                "text/event-stream": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test2.IterateResponse"
+                  }
+                }
+              },
+              "description": "stream of the elements, one per event or line"
+            },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "406": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Not Acceptable"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "tags": [
+            "test.Handler"
+          ]
+        }
+      },
+      "/v1/test/ws/echo/{room}": {
+        "get": {
+          "description": "Sends every client's message back.",
+          "operationId": "v1_test_ws_echo__room__get",
+          "parameters": [
+            {
+              "in": "path",
+              "name": "room",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "101": {
+              "description": "switched to WebSocket; every message is a JSON text frame"
+            },
+            "400": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ValidationError"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "426": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Upgrade Required"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "tags": [
+            "test.Handler"
+          ],
+          "x-websocket": {
+            "clientMessage": {
+              "$ref": "#/components/schemas/test.echoMessage"
+            },
+            "serverMessage": {
+              "$ref": "#/components/schemas/test.echoMessage"
+            }
+          }
+        }
+      },
+      "/v1/test/ws/ticks": {
+        "get": {
+          "description": "Sends a response per second, ignoring the client's messages.",
+          "operationId": "v1_test_ws_ticks_get",
+          "responses": {
+            "101": {
+              "description": "switched to WebSocket; every message is a JSON text frame"
+            },
+            "426": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Upgrade Required"
+            },
+            "500": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ErrorResponse"
+                  }
+                }
+              },
+              "description": "Internal Server Error"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "tags": [
+            "test.Handler"
+          ],
+          "x-websocket": {
+            "clientMessage": {
+              "type": "string"
+            },
+            "serverMessage": {
+              "$ref": "#/components/schemas/test2.IterateResponse"
+            }
+          }
+        }
       }
     },
     "tags": [
@@ -0,0 +1,455 @@
+// Code generated by utrack/pontoon. DO NOT EDIT.
+// Source: github.com/utrack/pontoon/test
+
+/* eslint-disable */
+
+/** ClientConfig points the operations to the service. */
+export interface ClientConfig {
+  /** Base URL of the service, i.e. "https://api.example.com". */
+  baseURL: string;
+  /** Replaces the global fetch. */
+  fetch?: typeof fetch;
+  /** Is applied to every request, i.e. to set the credentials. */
+  init?: RequestInit;
+}
+
+/** FieldError describes a single invalid value of the request. */
+export interface FieldError {
+  field: string;
+  in: string;
+  name: string;
+  reason: string;
+}
+
+/** ApiError is thrown when the service responds with an error. */
+export class ApiError extends Error {
+  /** HTTP status code of the response. */
+  readonly status: number;
+  /** Decoded JSON body of the response, or its text. */
+  readonly body: unknown;
+  /** Invalid values of the request, if any. */
+  readonly fields: FieldError[];
+
+  constructor(status: number, body: unknown) {
+    super(errorMessage(status, body));
+    this.name = "ApiError";
+    this.status = status;
+    this.body = body;
+    const b = (typeof body === "object" && body !== null ? body : {}) as Record<string, unknown>;
+    this.fields = (b.fields ?? b["invalid-params"] ?? []) as FieldError[];
+  }
+}
+
+function errorMessage(status: number, body: unknown): string {
+  if (typeof body === "object" && body !== null) {
+    const b = body as Record<string, unknown>;
+    const msg = b.error ?? b.detail ?? b.title;
+    if (typeof msg === "string") {
+      return status + ": " + msg;
+    }
+  }
+  if (typeof body === "string" && body !== "") {
+    return status + ": " + body;
+  }
+  return String(status);
+}
+
+interface Call {
+  method: string;
+  path: string;
+  query?: Record<string, unknown>;
+  headers?: Record<string, unknown>;
+  form?: Record<string, unknown>;
+  multipart?: boolean;
+  body?: unknown;
+  accept?: string;
+}
+
+/** values formats the parameter; unset values are left to the service's defaults. */
+function values(v: unknown): string[] {
+  if (v === undefined || v === null) {
+    return [];
+  }
+  if (Array.isArray(v)) {
+    return v.flatMap(values);
+  }
+  if (v instanceof Date) {
+    return [v.toISOString()];
+  }
+  return [String(v)];
+}
+
+function pathParam(v: unknown): string {
+  return encodeURIComponent(values(v).join(","));
+}
+
+function pathRest(v: unknown): string {
+  return values(v).join(",").split("/").map(encodeURIComponent).join("/");
+}
+
+async function call(cfg: ClientConfig, c: Call, init?: RequestInit): Promise<Response> {
+  const url = new URL(cfg.baseURL.replace(/\/+$/, "") + c.path);
+  for (const [k, v] of Object.entries(c.query ?? {})) {
+    for (const s of values(v)) {
+      url.searchParams.append(k, s);
+    }
+  }
+
+  const headers = new Headers(cfg.init?.headers);
+  new Headers(init?.headers).forEach((v, k) => headers.set(k, v));
+  for (const [k, v] of Object.entries(c.headers ?? {})) {
+    for (const s of values(v)) {
+      headers.append(k, s);
+    }
+  }
+  headers.set("Accept", c.accept ?? "application/json");
+
+  let body: BodyInit | undefined;
+  if (c.form !== undefined && c.multipart) {
+    const form = new FormData();
+    for (const [k, v] of Object.entries(c.form)) {
+      if (v instanceof Blob) {
+        form.append(k, v);
+        continue;
+      }
+      for (const s of values(v)) {
+        form.append(k, s);
+      }
+    }
+    body = form;
+  } else if (c.form !== undefined) {
+    const form = new URLSearchParams();
+    for (const [k, v] of Object.entries(c.form)) {
+      for (const s of values(v)) {
+        form.append(k, s);
+      }
+    }
+    body = form;
+  } else if (c.body !== undefined) {
+    headers.set("Content-Type", "application/json");
+    body = JSON.stringify(c.body);
+  }
+
+  const rsp = await (cfg.fetch ?? fetch)(url, { ...cfg.init, ...init, method: c.method, headers, body });
+  if (!rsp.ok) {
+    const text = await rsp.text();
+    let errBody: unknown = text;
+    try {
+      errBody = JSON.parse(text);
+    } catch {
+      // not a JSON body
+    }
+    throw new ApiError(rsp.status, errBody);
+  }
+  return rsp;
+}
+
+/** readNDJSON yields the elements of a streamed response. */
+async function* readNDJSON<T>(rsp: Response): AsyncGenerator<T> {
+  if (rsp.body === null) {
+    return;
+  }
+  const reader = rsp.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      buf += decoder.decode(value, { stream: !done });
+      let i: number;
+      while ((i = buf.indexOf("\n")) >= 0) {
+        const line = buf.slice(0, i).trim();
+        buf = buf.slice(i + 1);
+        if (line !== "") {
+          yield JSON.parse(line) as T;
+        }
+      }
+      if (done) {
+        break;
+      }
+    }
+    if (buf.trim() !== "") {
+      yield JSON.parse(buf) as T;
+    }
+  } finally {
+    // closes the stream if the caller stops early
+    await reader.cancel();
+  }
+}
+
+/**
+ * Request comment
+ * Request line 2
+ */
+export interface IterateRequest extends IterateEmbedded {
+  SliceStrings: string[] | null;
+  Maps: Record<string, Mapped>;
+  Recursive: IterateRequest | null;
+}
+
+/** Has an Embed comment */
+export interface IterateEmbedded {}
+
+export interface Mapped {}
+
+export interface IterateResponse {
+  resp: string;
+}
+
+/** Represents a 'raw' JSON struct without annotations with an embed no-annotated one */
+export interface NonAnnotJSON extends NonAnnotJSON2 {
+  foo: string;
+  email: string;
+  tags: string[] | null;
+  status: "active" | "archived" | "draft";
+  priority?: 1 | 2 | null;
+  /** A duration of another module, its constants aren't an enum. */
+  ttl: number;
+  /** Has a single constant, it isn't an enum. */
+  quantity: number;
+}
+
+export interface NonAnnotJSON2 {
+  bar: string;
+}
+
+export interface JsonWithArrayOfStructs {
+  Ret: DummyStruct[] | null;
+}
+
+export interface DummyStruct {
+  DummyField: string;
+  /** Identifies the struct. */
+  id: string;
+  code: string;
+  homepage: string;
+  score: number;
+  count: number;
+  labels: string[] | null;
+}
+
+/** Describes a JSON-marshaled request with additional 'in' directives. */
+export interface JsonWithDirectives {
+  with_default?: string;
+  RequiredOnly: string;
+}
+
+/** Returned when the resource was changed concurrently. */
+export interface ConflictError {
+  error: string;
+  version: number;
+}
+
+/** A message of the echo WebSocket. */
+export interface EchoMessage {
+  room: string;
+  text: string;
+}
+
+/**
+ * Request comment
+ * Request line 2
+ */
+export interface IterateRequestParams {
+  /**
+   * A token for the next page.
+   * Pass an empty page_token if you want to request the first page,
+   * and use a token from the response as page_token to get the next page.
+   */
+  page_token?: string;
+  foo?: number;
+  /**
+   * A maximum count of products on a page.
+   *
+   * Defaults to 20.
+   */
+  limit?: number;
+  /**
+   * Hides the products out of stock.
+   *
+   * Defaults to true.
+   */
+  in_stock?: boolean;
+  /** Describes Some Stuff(tm). Required field. */
+  local: string;
+  /** Defaults to foobarbaz. */
+  local_default?: string;
+  Recursive?: IterateRequest | null;
+}
+
+/**
+ * Comment
+ * Includes imported package
+ *
+ * `POST /v1/products/iterate/create`
+ * @throws {ApiError} if the call fails.
+ */
+export async function v1ProductsIterateCreatePost(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<IterateResponse | null> {
+  const rsp = await call(cfg, {
+    method: "POST",
+    path: "/v1/products/iterate/create",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+  return (await rsp.json()) as IterateResponse | null;
+}
+
+/**
+ * Comment
+ * Includes imported package
+ *
+ * `GET /v1/products/iterate`
+ * @throws {ApiError} if the call fails.
+ */
+export async function v1ProductsIterateGet(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<IterateResponse | null> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/products/iterate",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+  return (await rsp.json()) as IterateResponse | null;
+}
+
+/**
+ * Comment
+ * Includes imported package
+ *
+ * `POST /v1/products/iterate`
+ * @throws {ApiError} if the call fails.
+ */
+export async function v1ProductsIteratePost(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<IterateResponse | null> {
+  const rsp = await call(cfg, {
+    method: "POST",
+    path: "/v1/products/iterate",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+  return (await rsp.json()) as IterateResponse | null;
+}
+
+/**
+ * `POST /v1/test/get-nonannot-json-embed`
+ * @throws {ApiError} if the call fails.
+ */
+export async function nonAnnotIn(cfg: ClientConfig, body: NonAnnotJSON, init?: RequestInit): Promise<unknown> {
+  const rsp = await call(cfg, {
+    method: "POST",
+    path: "/v1/test/get-nonannot-json-embed",
+    body,
+  }, init);
+  return (await rsp.json()) as unknown;
+}
+
+/**
+ * `GET /v1/test/return/return-nothing`
+ * @throws {ApiError} if the call fails.
+ */
+export async function zeroReturn(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<void> {
+  await call(cfg, {
+    method: "GET",
+    path: "/v1/test/return/return-nothing",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+}
+
+/**
+ * `GET /v1/test/return/interface`
+ * @throws {ApiError} if the call fails.
+ */
+export async function ifaceReturn(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<unknown> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/test/return/interface",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+  return (await rsp.json()) as unknown;
+}
+
+/**
+ * `GET /v1/test/return/interface-any`
+ * @throws {ApiError} if the call fails.
+ */
+export async function ifaceReturnAny(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<unknown> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/test/return/interface-any",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+  return (await rsp.json()) as unknown;
+}
+
+/**
+ * `GET /v1/test/return/slice`
+ * @throws {ApiError} if the call fails.
+ */
+export async function sliceReturn(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<IterateResponse[] | null> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/test/return/slice",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+  return (await rsp.json()) as IterateResponse[] | null;
+}
+
+/**
+ * `GET /v1/test/return/slice-in-struct`
+ * @throws {ApiError} if the call fails.
+ */
+export async function sliceInObjReturn(cfg: ClientConfig, init?: RequestInit): Promise<JsonWithArrayOfStructs | null> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/test/return/slice-in-struct",
+  }, init);
+  return (await rsp.json()) as JsonWithArrayOfStructs | null;
+}
+
+/**
+ * `GET /v1/test/return/map`
+ * @throws {ApiError} if the call fails.
+ */
+export async function mapReturn(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): Promise<Record<string, IterateResponse>> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/test/return/map",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+  }, init);
+  return (await rsp.json()) as Record<string, IterateResponse>;
+}
+
+/**
+ * Sends a response per second until the client goes away.
+ *
+ * `GET /v1/test/return/stream`
+ * @throws {ApiError} if the call fails.
+ */
+export async function* streamReturn(cfg: ClientConfig, params: IterateRequestParams, init?: RequestInit): AsyncGenerator<IterateResponse> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/test/return/stream",
+    body: params.Recursive,
+    query: { page_token: params.page_token, foo: params.foo, limit: params.limit, in_stock: params.in_stock, local: params.local, local_default: params.local_default },
+    accept: "application/x-ndjson",
+  }, init);
+  yield* readNDJSON<IterateResponse>(rsp);
+}
+
+/**
+ * Responds with a custom error body.
+ *
+ * `GET /v1/test/request/jsonWithDirective`
+ * @throws {ApiError} if the call fails;
+ *   a 409 error's body is ConflictError.
+ */
+export async function jsonWithDirs(cfg: ClientConfig, body: JsonWithDirectives, init?: RequestInit): Promise<void> {
+  await call(cfg, {
+    method: "GET",
+    path: "/v1/test/request/jsonWithDirective",
+    body,
+  }, init);
+}
@@ -0,0 +1,306 @@
+// Code generated by utrack/pontoon. DO NOT EDIT.
+// Source: github.com/utrack/pontoon/test
+
+package test
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/utrack/pontoon/sdesc"
+	"github.com/utrack/pontoon/test2"
+)
+
+// HandlerClient calls Handler's endpoints over HTTP.
+// Zero values of the optional parameters aren't sent, leaving them
+// to the defaults; use pointer fields to send them explicitly.
+type HandlerClient struct {
+	c *sdesc.Client
+}
+
+// NewHandlerClient creates a client of Handler served at baseURL.
+func NewHandlerClient(baseURL string, opts ...sdesc.ClientOption) (*HandlerClient, error) {
+	c, err := sdesc.NewClient(baseURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &HandlerClient{c: c}, nil
+}
+
+// V1ProductsIterateCreatePost calls POST /v1/products/iterate/create.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) V1ProductsIterateCreatePost(ctx context.Context, in iterateRequest) (*test2.IterateResponse, error) {
+	req := c.c.NewRequest("POST", "/v1/products/iterate/create")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	var out *test2.IterateResponse
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// V1ProductsIterateGet calls GET /v1/products/iterate.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) V1ProductsIterateGet(ctx context.Context, in iterateRequest) (*test2.IterateResponse, error) {
+	req := c.c.NewRequest("GET", "/v1/products/iterate")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	var out *test2.IterateResponse
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// V1ProductsIteratePost calls POST /v1/products/iterate.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) V1ProductsIteratePost(ctx context.Context, in iterateRequest) (*test2.IterateResponse, error) {
+	req := c.c.NewRequest("POST", "/v1/products/iterate")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	var out *test2.IterateResponse
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// NonAnnotIn calls POST /v1/test/get-nonannot-json-embed.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) NonAnnotIn(ctx context.Context, in nonAnnotJSON) (interface{}, error) {
+	req := c.c.NewRequest("POST", "/v1/test/get-nonannot-json-embed")
+	req.Body(in)
+	var out interface{}
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// ZeroReturn calls GET /v1/test/return/return-nothing.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) ZeroReturn(ctx context.Context, in iterateRequest) error {
+	req := c.c.NewRequest("GET", "/v1/test/return/return-nothing")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	return c.c.Do(ctx, req, nil)
+}
+
+// IfaceReturn calls GET /v1/test/return/interface.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) IfaceReturn(ctx context.Context, in iterateRequest) (interface{}, error) {
+	req := c.c.NewRequest("GET", "/v1/test/return/interface")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	var out interface{}
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// IfaceReturnAny calls GET /v1/test/return/interface-any.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) IfaceReturnAny(ctx context.Context, in iterateRequest) (any, error) {
+	req := c.c.NewRequest("GET", "/v1/test/return/interface-any")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	var out any
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// SliceReturn calls GET /v1/test/return/slice.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) SliceReturn(ctx context.Context, in iterateRequest) ([]test2.IterateResponse, error) {
+	req := c.c.NewRequest("GET", "/v1/test/return/slice")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	var out []test2.IterateResponse
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// SliceInObjReturn calls GET /v1/test/return/slice-in-struct.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) SliceInObjReturn(ctx context.Context) (*jsonWithArrayOfStructs, error) {
+	req := c.c.NewRequest("GET", "/v1/test/return/slice-in-struct")
+	var out *jsonWithArrayOfStructs
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// MapReturn calls GET /v1/test/return/map.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) MapReturn(ctx context.Context, in iterateRequest) (map[string]test2.IterateResponse, error) {
+	req := c.c.NewRequest("GET", "/v1/test/return/map")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	var out map[string]test2.IterateResponse
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// StreamReturn calls GET /v1/test/return/stream.
+// The stream's channel is closed once it ends or ctx is done,
+// check its Err then.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) StreamReturn(ctx context.Context, in iterateRequest) (*sdesc.Stream[test2.IterateResponse], error) {
+	req := c.c.NewRequest("GET", "/v1/test/return/stream")
+	if in.iterateEmbedded.PageToken != "" {
+		req.Query("page_token", in.iterateEmbedded.PageToken)
+	}
+	if in.Foo != 0 {
+		req.Query("foo", strconv.FormatInt(in.Foo, 10))
+	}
+	if in.Limit != 0 {
+		req.Query("limit", strconv.FormatInt(int64(in.Limit), 10))
+	}
+	if in.InStock {
+		req.Query("in_stock", strconv.FormatBool(in.InStock))
+	}
+	req.Query("local", in.Local)
+	req.Query("local_default", in.LocalWithDefault)
+	if in.Recursive != nil {
+		req.Body(in.Recursive)
+	}
+	return sdesc.DoStream[test2.IterateResponse](ctx, c.c, req)
+}
+
+// JsonWithDirs calls GET /v1/test/request/jsonWithDirective.
+// Failed calls return *sdesc.ClientError.
+// A 409 response's body decodes into conflictError via its DecodeBody.
+func (c *HandlerClient) JsonWithDirs(ctx context.Context, in jsonWithDirectives) error {
+	req := c.c.NewRequest("GET", "/v1/test/request/jsonWithDirective")
+	req.Body(in)
+	return c.c.Do(ctx, req, nil)
+}
@@ -1,26 +1,53 @@
 package test
 
 import (
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/utrack/pontoon/sdesc"
 )
 
 func (h Handler) RegisterHTTP(mux sdesc.HTTPRouter) {
-	// POST
-	mux.MethodFunc(http.MethodPost, "/v1/products/iterate/create", h.iterateProducts)
-	mux.MethodFunc(http.MethodPost, "/v1/test/get-nonannot-json-embed", h.nonAnnotIn)
-	// GET
-	mux.MethodFunc(http.MethodGet, "/v1/products/iterate", h.iterateProducts)
-	// Same path as above, different ops
-	mux.MethodFunc(http.MethodPost, "/v1/products/iterate", h.iterateProducts)
-	// Different return types
-	mux.MethodFunc(http.MethodGet, "/v1/test/return/return-nothing", h.zeroReturn)
-	mux.MethodFunc(http.MethodGet, "/v1/test/return/interface", h.ifaceReturn)
-	mux.MethodFunc(http.MethodGet, "/v1/test/return/interface-any", h.ifaceReturnAny)
-	mux.MethodFunc(http.MethodGet, "/v1/test/return/slice", h.sliceReturn)
-	mux.MethodFunc(http.MethodGet, "/v1/test/return/slice-in-struct", h.sliceInObjReturn)
-	mux.MethodFunc(http.MethodGet, "/v1/test/return/map", h.mapReturn)
-
-	mux.MethodFunc(http.MethodGet, "/v1/test/request/jsonWithDirective", h.jsonWithDirs)
+	mux.Route("/v1/products/iterate", func(r sdesc.HTTPRouter) {
+		// POST
+		r.MethodFunc(http.MethodPost, "/create", h.iterateProducts)
+		// GET
+		r.MethodFunc(http.MethodGet, "", h.iterateProducts)
+		// Same path as above, different ops
+		r.MethodFunc(http.MethodPost, "", h.iterateProducts)
+	})
+
+	mux.Route("/v1/test", func(r sdesc.HTTPRouter) {
+		r.MethodFunc(http.MethodPost, "/get-nonannot-json-embed", h.nonAnnotIn)
+
+		// Different return types
+		ret := r.With(logCalls)
+		ret.MethodFunc(http.MethodGet, "/return/return-nothing", h.zeroReturn)
+		ret.MethodFunc(http.MethodGet, "/return/interface", h.ifaceReturn)
+		ret.MethodFunc(http.MethodGet, "/return/interface-any", h.ifaceReturnAny,
+			sdesc.WithDeprecated())
+		ret.MethodFunc(http.MethodGet, "/return/slice", h.sliceReturn,
+			sdesc.WithTimeout(5*time.Second))
+		ret.MethodFunc(http.MethodGet, "/return/slice-in-struct", h.sliceInObjReturn)
+		ret.MethodFunc(http.MethodGet, "/return/map", h.mapReturn)
+		ret.MethodFunc(http.MethodGet, "/return/stream", h.streamReturn)
+
+		r.With(logCalls).MethodFunc(http.MethodGet, "/request/jsonWithDirective", h.jsonWithDirs,
+			sdesc.WithTags("directives"),
+			sdesc.WithOperationID("getJSONWithDirectives"))
+
+		// WebSockets
+		sdesc.HandleWebSocket(r, "/ws/echo/{room}", h.echo)
+		r.WebSocket("/ws/ticks", h.ticks,
+			sdesc.WithOrigins("https://example.com"))
+	})
+}
+
+func logCalls(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ri, _ := sdesc.RouteFromContext(r.Context())
+		log.Printf("calling %v", ri.OperationID)
+		next.ServeHTTP(w, r)
+	})
 }
@@ -0,0 +1,148 @@
+package test3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/sdesc"
+)
+
+// TestClient checks that the generated client's requests
+// match the spec and are bound by the Router as they were built.
+func TestClient(t *testing.T) {
+	v, err := sdesc.NewRequestValidator(Handler{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := sdesc.NewRouter(
+		sdesc.WithAuthenticator(testAuth),
+		sdesc.WithGlobalMiddlewares(v.Middleware))
+	r.Register(Handler{})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	var sent *http.Request
+	newClient := func(t *testing.T, token string) *HandlerClient {
+		c, err := NewHandlerClient(srv.URL,
+			sdesc.WithHTTPClient(srv.Client()),
+			sdesc.WithRequestEditor(func(r *http.Request) error {
+				if token != "" {
+					r.Header.Set("Authorization", "Bearer "+token)
+				}
+				sent = r
+				return nil
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	ctx := context.Background()
+
+	t.Run("path, query, header and body", func(t *testing.T) {
+		tests := []struct {
+			in        updateRequest
+			wantQuery string
+			want      updatedItem
+		}{
+			{
+				in: updateRequest{
+					ID:      "item 1",
+					Version: 3,
+					Tags:    []string{"new", "sale"},
+					Item:    itemUpdate{Name: "Chair"},
+				},
+				// the zero Notify is left to its default
+				wantQuery: "tag=new&tag=sale",
+				want:      updatedItem{ID: "item 1", Name: "Chair", Version: 4, Tags: []string{"new", "sale"}, Notified: true},
+			},
+			{
+				in:        updateRequest{ID: "2", Version: 1, Notify: true},
+				wantQuery: "notify=true",
+				want:      updatedItem{ID: "2", Version: 2, Notified: true},
+			},
+		}
+		for _, tt := range tests {
+			got, err := newClient(t, "token").UpdateItem(ctx, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent.URL.EscapedPath() != "/v1/items/"+url.PathEscape(tt.in.ID) {
+				t.Errorf("got path %v", sent.URL.EscapedPath())
+			}
+			if sent.URL.RawQuery != tt.wantQuery {
+				t.Errorf("got query %q, want %q", sent.URL.RawQuery, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		}
+	})
+
+	t.Run("form", func(t *testing.T) {
+		tests := []noteRequest{
+			{ItemID: "1", Text: "too expensive", Pinned: true},
+			{ItemID: "2", Text: "sold out"},
+		}
+		for _, in := range tests {
+			got, err := newClient(t, "token").AddNote(ctx, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ct := sent.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+				t.Errorf("got Content-Type %q", ct)
+			}
+			want := note{ItemID: in.ItemID, Text: in.Text, Pinned: in.Pinned}
+			if *got != want {
+				t.Errorf("got %+v, want %+v", *got, want)
+			}
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := newClient(t, "token").UpdateItem(ctx, updateRequest{ID: "1"})
+
+		var ce *sdesc.ClientError
+		if !errors.As(err, &ce) {
+			t.Fatalf("got error %v, want *sdesc.ClientError", err)
+		}
+		if ce.StatusCode() != http.StatusBadRequest || ce.Problem == nil {
+			t.Errorf("got %v, problem %+v", ce, ce.Problem)
+		}
+		var ve *sdesc.ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("got error %v, want *sdesc.ValidationError", err)
+		}
+		if len(ve.Fields) != 1 || ve.Fields[0].In != "header" || ve.Fields[0].Name != "X-Item-Version" {
+			t.Errorf("got fields %+v", ve.Fields)
+		}
+	})
+
+	t.Run("error body", func(t *testing.T) {
+		_, err := newClient(t, "token").GetItem(ctx, getRequest{ID: "1"})
+
+		var ce *sdesc.ClientError
+		if !errors.As(err, &ce) {
+			t.Fatalf("got error %v, want *sdesc.ClientError", err)
+		}
+		var p sdesc.ProblemDetails
+		if err := ce.DecodeBody(&p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Status != http.StatusNotFound || p.Detail != "no such item" || ce.Message != p.Detail {
+			t.Errorf("got problem %+v, message %q", p, ce.Message)
+		}
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := newClient(t, "").GetItem(ctx, getRequest{ID: "1"})
+		if sdesc.StatusCode(err) != http.StatusUnauthorized {
+			t.Errorf("got error %v, want 401", err)
+		}
+	})
+}
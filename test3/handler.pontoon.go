@@ -0,0 +1,796 @@
+// Code generated by utrack/pontoon. DO NOT EDIT.
+// Source: github.com/utrack/pontoon/test3
+
+package test3
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/utrack/pontoon/sdesc"
+)
+
+func (s Handler) OpenAPI() string {
+	return `{
+    "components": {
+      "schemas": {
+        "sdesc.FieldError": {
+          "description": "Single invalid value of the request",
+          "properties": {
+            "field": {
+              "description": "Go name of the input struct's field",
+              "type": "string"
+            },
+            "in": {
+              "description": "Location of the value",
+              "enum": [
+                "query",
+                "header",
+                "path",
+                "form",
+                "body"
+              ],
+              "type": "string"
+            },
+            "name": {
+              "description": "Parameter name or JSON field name",
+              "type": "string"
+            },
+            "reason": {
+              "description": "What's wrong with the value",
+              "type": "string"
+            }
+          },
+          "required": [
+            "field",
+            "in",
+            "name",
+            "reason"
+          ],
+          "type": "object"
+        },
+        "sdesc.ProblemDetails": {
+          "description": "RFC 7807 problem details",
+          "properties": {
+            "detail": {
+              "description": "Explanation of this occurrence of the problem",
+              "type": "string"
+            },
+            "instance": {
+              "description": "URI reference of this occurrence of the problem",
+              "format": "uri-reference",
+              "type": "string"
+            },
+            "invalid-params": {
+              "items": {
+                "$ref": "#/components/schemas/sdesc.FieldError"
+              },
+              "type": "array"
+            },
+            "status": {
+              "description": "HTTP status code",
+              "type": "integer"
+            },
+            "title": {
+              "description": "Short summary of the problem type",
+              "type": "string"
+            },
+            "type": {
+              "description": "URI reference that identifies the problem type",
+              "format": "uri-reference",
+              "type": "string"
+            }
+          },
+          "required": [
+            "type",
+            "title",
+            "status"
+          ],
+          "type": "object"
+        },
+        "test3.item": {
+          "properties": {
+            "id": {
+              "example": "item-1",
+              "type": "string"
+            }
+          },
+          "type": "object"
+        },
+        "test3.itemUpdate": {
+          "properties": {
+            "name": {
+              "type": "string"
+            }
+          },
+          "type": "object"
+        },
+        "test3.note": {
+          "description": "A remark on an item.",
+          "properties": {
+            "item_id": {
+              "type": "string"
+            },
+            "pinned": {
+              "type": "boolean"
+            },
+            "text": {
+              "type": "string"
+            }
+          },
+          "type": "object"
+        },
+        "test3.updatedItem": {
+          "description": "An item after the update.",
+          "properties": {
+            "id": {
+              "example": "item-1",
+              "type": "string"
+            },
+            "name": {
+              "type": "string"
+            },
+            "notified": {
+              "type": "boolean"
+            },
+            "tags": {
+              "items": {
+                "type": "string"
+              },
+              "nullable": true,
+              "type": "array"
+            },
+            "version": {
+              "format": "int64",
+              "type": "integer"
+            }
+          },
+          "type": "object"
+        }
+      },
+      "securitySchemes": {
+        "apiKey": {
+          "in": "header",
+          "name": "X-API-Key",
+          "type": "apiKey"
+        },
+        "jwt": {
+          "bearerFormat": "JWT",
+          "scheme": "bearer",
+          "type": "http"
+        }
+      }
+    },
+    "info": {
+      "title": "github.com/utrack/pontoon/test3",
+      "version": "1-autogen"
+    },
+    "openapi": "3.1.0",
+    "paths": {
+      "/v1/items": {
+        "get": {
+          "description": "Returns every item; it's public.",
+          "operationId": "v1_items_get",
+          "responses": {
+            "200": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "items": {
+                      "$ref": "#/components/schemas/test3.item"
+                    },
+                    "nullable": true,
+                    "type": "array"
+                  }
+                },
+                "application/xml": {
+                  "schema": {
+                    "items": {
+                      "$ref": "#/components/schemas/test3.item"
+                    },
+                    "nullable": true,
+                    "type": "array"
+                  }
+                }
+              },
+              "description": "success"
+            },
+            "406": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Not Acceptable"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "security": [],
+          "tags": [
+            "test3.Handler"
+          ]
+        }
+      },
+      "/v1/items/{id}": {
+        "delete": {
+          "description": "Removes an item by its ID.",
+          "operationId": "v1_items__id__delete",
+          "parameters": [
+            {
+              "in": "path",
+              "name": "id",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "content": {
+                "application/json": {},
+                "application/xml": {}
+              },
+              "description": "success"
+            },
+            "400": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "401": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Unauthorized"
+            },
+            "403": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Forbidden"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "security": [
+            {
+              "jwt": [
+                "items:write"
+              ]
+            },
+            {
+              "apiKey": []
+            }
+          ],
+          "tags": [
+            "test3.Handler"
+          ]
+        },
+        "get": {
+          "description": "Returns an item by its ID.",
+          "operationId": "v1_items__id__get",
+          "parameters": [
+            {
+              "in": "path",
+              "name": "id",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "responses": {
+            "200": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test3.item"
+                  }
+                },
+                "application/xml": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test3.item"
+                  }
+                }
+              },
+              "description": "success"
+            },
+            "400": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "401": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Unauthorized"
+            },
+            "403": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Forbidden"
+            },
+            "404": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Not Found"
+            },
+            "406": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Not Acceptable"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "security": [
+            {
+              "jwt": [
+                "items:read"
+              ]
+            }
+          ],
+          "tags": [
+            "test3.Handler"
+          ]
+        },
+        "put": {
+          "description": "Replaces an item's name and tags.",
+          "operationId": "v1_items__id__put",
+          "parameters": [
+            {
+              "in": "path",
+              "name": "id",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            },
+            {
+              "description": "The item's version the update is based on.",
+              "in": "header",
+              "name": "X-Item-Version",
+              "required": true,
+              "schema": {
+                "description": "The item's version the update is based on.",
+                "format": "int64",
+                "minimum": 1,
+                "type": "integer"
+              }
+            },
+            {
+              "description": "Tells the item's watchers about the update.",
+              "in": "query",
+              "name": "notify",
+              "schema": {
+                "default": true,
+                "description": "Tells the item's watchers about the update.",
+                "type": "boolean"
+              }
+            },
+            {
+              "description": "Replace the item's tags.",
+              "in": "query",
+              "name": "tag",
+              "schema": {
+                "items": {
+                  "type": "string"
+                },
+                "nullable": true,
+                "type": "array"
+              }
+            }
+          ],
+          "requestBody": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/test3.itemUpdate"
+                }
+              },
+              "application/xml": {
+                "schema": {
+                  "$ref": "#/components/schemas/test3.itemUpdate"
+                }
+              }
+            }
+          },
+          "responses": {
+            "200": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test3.updatedItem"
+                  }
+                },
+                "application/xml": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test3.updatedItem"
+                  }
+                }
+              },
+              "description": "success"
+            },
+            "400": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "401": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Unauthorized"
+            },
+            "403": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Forbidden"
+            },
+            "406": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Not Acceptable"
+            },
+            "415": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Unsupported Media Type"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "security": [
+            {
+              "jwt": [
+                "items:read"
+              ]
+            }
+          ],
+          "tags": [
+            "test3.Handler"
+          ]
+        }
+      },
+      "/v1/items/{id}/notes": {
+        "post": {
+          "description": "Adds a note to an item.",
+          "operationId": "v1_items__id__notes_post",
+          "parameters": [
+            {
+              "in": "path",
+              "name": "id",
+              "required": true,
+              "schema": {
+                "type": "string"
+              }
+            }
+          ],
+          "requestBody": {
+            "content": {
+              "application/x-www-form-urlencoded": {
+                "schema": {
+                  "properties": {
+                    "pinned": {
+                      "type": "boolean"
+                    },
+                    "text": {
+                      "type": "string"
+                    }
+                  },
+                  "required": [
+                    "text"
+                  ],
+                  "type": "object"
+                }
+              }
+            }
+          },
+          "responses": {
+            "200": {
+              "content": {
+                "application/json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test3.note"
+                  }
+                },
+                "application/xml": {
+                  "schema": {
+                    "$ref": "#/components/schemas/test3.note"
+                  }
+                }
+              },
+              "description": "success"
+            },
+            "400": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "invalid request"
+            },
+            "401": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Unauthorized"
+            },
+            "403": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Forbidden"
+            },
+            "406": {
+              "content": {
+                "application/problem+json": {
+                  "schema": {
+                    "$ref": "#/components/schemas/sdesc.ProblemDetails"
+                  }
+                }
+              },
+              "description": "Not Acceptable"
+            },
+            "default": {
+              "description": ""
+            }
+          },
+          "security": [
+            {
+              "jwt": [
+                "items:read"
+              ]
+            }
+          ],
+          "tags": [
+            "test3.Handler"
+          ]
+        }
+      }
+    },
+    "tags": [
+      {
+        "description": "Reports its errors as RFC 7807 problems,\nrequires authentication and speaks both JSON and XML.",
+        "name": "test3.Handler"
+      }
+    ]
+  }`
+}
+
+// RegisterHTTPHandlers registers Handler's endpoints in the mux
+// using generated bindings instead of reflection.
+func (s Handler) RegisterHTTPHandlers(mux *http.ServeMux, opts ...sdesc.RouterOption) {
+	sdesc.RegisterCompiled(mux, &s, []sdesc.CompiledRoute{
+		{
+			Method:      "GET",
+			Pattern:     "/v1/items",
+			OperationID: "v1_items_get",
+			NoSecurity:  true,
+			Output:      true,
+			Serve:       s.pontoonServeListItems,
+		},
+		{
+			Method:      "GET",
+			Pattern:     "/v1/items/{id}",
+			OperationID: "v1_items__id__get",
+			Output:      true,
+			Serve:       s.pontoonServeGetItem,
+		},
+		{
+			Method:      "PUT",
+			Pattern:     "/v1/items/{id}",
+			OperationID: "v1_items__id__put",
+			Output:      true,
+			Serve:       s.pontoonServeUpdateItem,
+		},
+		{
+			Method:      "POST",
+			Pattern:     "/v1/items/{id}/notes",
+			OperationID: "v1_items__id__notes_post",
+			Output:      true,
+			Serve:       s.pontoonServeAddNote,
+		},
+		{
+			Method:      "DELETE",
+			Pattern:     "/v1/items/{id}",
+			OperationID: "v1_items__id__delete",
+			Security: []sdesc.SecurityRequirement{
+				{Scheme: "jwt", Scopes: []string{"items:write"}},
+				{Scheme: "apiKey", Scopes: nil},
+			},
+			Serve: s.pontoonServeDeleteItem,
+		},
+	}, opts...)
+}
+
+func (s Handler) pontoonServeListItems(w http.ResponseWriter, r *http.Request) error {
+	out, err := s.listItems(r)
+	if err != nil {
+		return err
+	}
+	sdesc.WriteResult(w, r, out)
+	return nil
+}
+
+func (s Handler) pontoonServeGetItem(w http.ResponseWriter, r *http.Request) error {
+	var in getRequest
+	bd := sdesc.NewBinding(r, false)
+	if v, ok := bd.Value("ID", "path", "id", true, ""); ok {
+		in.ID = v
+	}
+	if err := bd.Err(); err != nil {
+		return err
+	}
+
+	out, err := s.getItem(r, in)
+	if err != nil {
+		return err
+	}
+	sdesc.WriteResult(w, r, out)
+	return nil
+}
+
+func (s Handler) pontoonServeUpdateItem(w http.ResponseWriter, r *http.Request) error {
+	var in updateRequest
+	bd := sdesc.NewBinding(r, false)
+	if v, ok := bd.Value("ID", "path", "id", true, ""); ok {
+		in.ID = v
+	}
+	if v, ok := bd.Value("Version", "header", "X-Item-Version", true, ""); ok {
+		if x, err := strconv.ParseInt(v, 10, 0); err != nil {
+			bd.Fail("Version", "header", "X-Item-Version", err.Error())
+		} else {
+			in.Version = int(x)
+		}
+	}
+	if v, ok := bd.Value("Notify", "query", "notify", false, "true"); ok {
+		if x, err := strconv.ParseBool(v); err != nil {
+			bd.Fail("Notify", "query", "notify", err.Error())
+		} else {
+			in.Notify = x
+		}
+	}
+	if vs, ok := bd.Values("Tags", "query", "tag", false, ""); ok {
+		in.Tags = make([]string, len(vs))
+		for i, v := range vs {
+			in.Tags[i] = v
+		}
+	}
+	bd.Body("Item", false, &in.Item)
+	if err := bd.Err(); err != nil {
+		return err
+	}
+
+	out, err := s.updateItem(r, in)
+	if err != nil {
+		return err
+	}
+	sdesc.WriteResult(w, r, out)
+	return nil
+}
+
+func (s Handler) pontoonServeAddNote(w http.ResponseWriter, r *http.Request) error {
+	var in noteRequest
+	bd := sdesc.NewBinding(r, true)
+	if v, ok := bd.Value("ItemID", "path", "id", true, ""); ok {
+		in.ItemID = v
+	}
+	if v, ok := bd.Value("Text", "form", "text", true, ""); ok {
+		in.Text = v
+	}
+	if v, ok := bd.Value("Pinned", "form", "pinned", false, ""); ok {
+		if x, err := strconv.ParseBool(v); err != nil {
+			bd.Fail("Pinned", "form", "pinned", err.Error())
+		} else {
+			in.Pinned = x
+		}
+	}
+	if err := bd.Err(); err != nil {
+		return err
+	}
+
+	out, err := s.addNote(r, in)
+	if err != nil {
+		return err
+	}
+	sdesc.WriteResult(w, r, out)
+	return nil
+}
+
+func (s Handler) pontoonServeDeleteItem(w http.ResponseWriter, r *http.Request) error {
+	var in getRequest
+	bd := sdesc.NewBinding(r, false)
+	if v, ok := bd.Value("ID", "path", "id", true, ""); ok {
+		in.ID = v
+	}
+	if err := bd.Err(); err != nil {
+		return err
+	}
+
+	if err := s.deleteItem(sdesc.CallContext(w, r), in); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
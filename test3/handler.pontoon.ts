@@ -0,0 +1,300 @@
+// Code generated by utrack/pontoon. DO NOT EDIT.
+// Source: github.com/utrack/pontoon/test3
+
+/* eslint-disable */
+
+/** ClientConfig points the operations to the service. */
+export interface ClientConfig {
+  /** Base URL of the service, i.e. "https://api.example.com". */
+  baseURL: string;
+  /** Replaces the global fetch. */
+  fetch?: typeof fetch;
+  /** Is applied to every request, i.e. to set the credentials. */
+  init?: RequestInit;
+}
+
+/** FieldError describes a single invalid value of the request. */
+export interface FieldError {
+  field: string;
+  in: string;
+  name: string;
+  reason: string;
+}
+
+/** ApiError is thrown when the service responds with an error. */
+export class ApiError extends Error {
+  /** HTTP status code of the response. */
+  readonly status: number;
+  /** Decoded JSON body of the response, or its text. */
+  readonly body: unknown;
+  /** Invalid values of the request, if any. */
+  readonly fields: FieldError[];
+
+  constructor(status: number, body: unknown) {
+    super(errorMessage(status, body));
+    this.name = "ApiError";
+    this.status = status;
+    this.body = body;
+    const b = (typeof body === "object" && body !== null ? body : {}) as Record<string, unknown>;
+    this.fields = (b.fields ?? b["invalid-params"] ?? []) as FieldError[];
+  }
+}
+
+function errorMessage(status: number, body: unknown): string {
+  if (typeof body === "object" && body !== null) {
+    const b = body as Record<string, unknown>;
+    const msg = b.error ?? b.detail ?? b.title;
+    if (typeof msg === "string") {
+      return status + ": " + msg;
+    }
+  }
+  if (typeof body === "string" && body !== "") {
+    return status + ": " + body;
+  }
+  return String(status);
+}
+
+interface Call {
+  method: string;
+  path: string;
+  query?: Record<string, unknown>;
+  headers?: Record<string, unknown>;
+  form?: Record<string, unknown>;
+  multipart?: boolean;
+  body?: unknown;
+  accept?: string;
+}
+
+/** values formats the parameter; unset values are left to the service's defaults. */
+function values(v: unknown): string[] {
+  if (v === undefined || v === null) {
+    return [];
+  }
+  if (Array.isArray(v)) {
+    return v.flatMap(values);
+  }
+  if (v instanceof Date) {
+    return [v.toISOString()];
+  }
+  return [String(v)];
+}
+
+function pathParam(v: unknown): string {
+  return encodeURIComponent(values(v).join(","));
+}
+
+function pathRest(v: unknown): string {
+  return values(v).join(",").split("/").map(encodeURIComponent).join("/");
+}
+
+async function call(cfg: ClientConfig, c: Call, init?: RequestInit): Promise<Response> {
+  const url = new URL(cfg.baseURL.replace(/\/+$/, "") + c.path);
+  for (const [k, v] of Object.entries(c.query ?? {})) {
+    for (const s of values(v)) {
+      url.searchParams.append(k, s);
+    }
+  }
+
+  const headers = new Headers(cfg.init?.headers);
+  new Headers(init?.headers).forEach((v, k) => headers.set(k, v));
+  for (const [k, v] of Object.entries(c.headers ?? {})) {
+    for (const s of values(v)) {
+      headers.append(k, s);
+    }
+  }
+  headers.set("Accept", c.accept ?? "application/json");
+
+  let body: BodyInit | undefined;
+  if (c.form !== undefined && c.multipart) {
+    const form = new FormData();
+    for (const [k, v] of Object.entries(c.form)) {
+      if (v instanceof Blob) {
+        form.append(k, v);
+        continue;
+      }
+      for (const s of values(v)) {
+        form.append(k, s);
+      }
+    }
+    body = form;
+  } else if (c.form !== undefined) {
+    const form = new URLSearchParams();
+    for (const [k, v] of Object.entries(c.form)) {
+      for (const s of values(v)) {
+        form.append(k, s);
+      }
+    }
+    body = form;
+  } else if (c.body !== undefined) {
+    headers.set("Content-Type", "application/json");
+    body = JSON.stringify(c.body);
+  }
+
+  const rsp = await (cfg.fetch ?? fetch)(url, { ...cfg.init, ...init, method: c.method, headers, body });
+  if (!rsp.ok) {
+    const text = await rsp.text();
+    let errBody: unknown = text;
+    try {
+      errBody = JSON.parse(text);
+    } catch {
+      // not a JSON body
+    }
+    throw new ApiError(rsp.status, errBody);
+  }
+  return rsp;
+}
+
+/** readNDJSON yields the elements of a streamed response. */
+async function* readNDJSON<T>(rsp: Response): AsyncGenerator<T> {
+  if (rsp.body === null) {
+    return;
+  }
+  const reader = rsp.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      buf += decoder.decode(value, { stream: !done });
+      let i: number;
+      while ((i = buf.indexOf("\n")) >= 0) {
+        const line = buf.slice(0, i).trim();
+        buf = buf.slice(i + 1);
+        if (line !== "") {
+          yield JSON.parse(line) as T;
+        }
+      }
+      if (done) {
+        break;
+      }
+    }
+    if (buf.trim() !== "") {
+      yield JSON.parse(buf) as T;
+    }
+  } finally {
+    // closes the stream if the caller stops early
+    await reader.cancel();
+  }
+}
+
+export interface Item {
+  id: string;
+}
+
+export interface ItemUpdate {
+  name: string;
+}
+
+/** An item after the update. */
+export interface UpdatedItem {
+  id: string;
+  name: string;
+  version: number;
+  tags: string[] | null;
+  notified: boolean;
+}
+
+/** A remark on an item. */
+export interface Note {
+  item_id: string;
+  text: string;
+  pinned: boolean;
+}
+
+export interface GetRequestParams {
+  id: string;
+}
+
+export interface UpdateRequestParams {
+  id: string;
+  /** The item's version the update is based on. */
+  "X-Item-Version": number;
+  /**
+   * Tells the item's watchers about the update.
+   *
+   * Defaults to true.
+   */
+  notify?: boolean;
+  /** Replace the item's tags. */
+  tag?: string[];
+  Item?: ItemUpdate;
+}
+
+export interface NoteRequestParams {
+  id: string;
+  text: string;
+  pinned?: boolean;
+}
+
+/**
+ * Returns every item; it's public.
+ *
+ * `GET /v1/items`
+ * @throws {ApiError} if the call fails.
+ */
+export async function listItems(cfg: ClientConfig, init?: RequestInit): Promise<Item[] | null> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/items",
+  }, init);
+  return (await rsp.json()) as Item[] | null;
+}
+
+/**
+ * Returns an item by its ID.
+ *
+ * `GET /v1/items/{id}`
+ * @throws {ApiError} if the call fails.
+ */
+export async function getItem(cfg: ClientConfig, params: GetRequestParams, init?: RequestInit): Promise<Item | null> {
+  const rsp = await call(cfg, {
+    method: "GET",
+    path: "/v1/items/" + pathParam(params.id),
+  }, init);
+  return (await rsp.json()) as Item | null;
+}
+
+/**
+ * Replaces an item's name and tags.
+ *
+ * `PUT /v1/items/{id}`
+ * @throws {ApiError} if the call fails.
+ */
+export async function updateItem(cfg: ClientConfig, params: UpdateRequestParams, init?: RequestInit): Promise<UpdatedItem | null> {
+  const rsp = await call(cfg, {
+    method: "PUT",
+    path: "/v1/items/" + pathParam(params.id),
+    body: params.Item,
+    query: { notify: params.notify, tag: params.tag },
+    headers: { "X-Item-Version": params["X-Item-Version"] },
+  }, init);
+  return (await rsp.json()) as UpdatedItem | null;
+}
+
+/**
+ * Adds a note to an item.
+ *
+ * `POST /v1/items/{id}/notes`
+ * @throws {ApiError} if the call fails.
+ */
+export async function addNote(cfg: ClientConfig, params: NoteRequestParams, init?: RequestInit): Promise<Note | null> {
+  const rsp = await call(cfg, {
+    method: "POST",
+    path: "/v1/items/" + pathParam(params.id) + "/notes",
+    form: { text: params.text, pinned: params.pinned },
+  }, init);
+  return (await rsp.json()) as Note | null;
+}
+
+/**
+ * Removes an item by its ID.
+ *
+ * `DELETE /v1/items/{id}`
+ * @throws {ApiError} if the call fails.
+ */
+export async function deleteItem(cfg: ClientConfig, params: GetRequestParams, init?: RequestInit): Promise<void> {
+  await call(cfg, {
+    method: "DELETE",
+    path: "/v1/items/" + pathParam(params.id),
+  }, init);
+}
@@ -0,0 +1,94 @@
+// Code generated by utrack/pontoon. DO NOT EDIT.
+// Source: github.com/utrack/pontoon/test3
+
+package test3
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/utrack/pontoon/sdesc"
+)
+
+// HandlerClient calls Handler's endpoints over HTTP.
+// Zero values of the optional parameters aren't sent, leaving them
+// to the defaults; use pointer fields to send them explicitly.
+type HandlerClient struct {
+	c *sdesc.Client
+}
+
+// NewHandlerClient creates a client of Handler served at baseURL.
+func NewHandlerClient(baseURL string, opts ...sdesc.ClientOption) (*HandlerClient, error) {
+	c, err := sdesc.NewClient(baseURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &HandlerClient{c: c}, nil
+}
+
+// ListItems calls GET /v1/items.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) ListItems(ctx context.Context) ([]item, error) {
+	req := c.c.NewRequest("GET", "/v1/items")
+	var out []item
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// GetItem calls GET /v1/items/{id}.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) GetItem(ctx context.Context, in getRequest) (*item, error) {
+	req := c.c.NewRequest("GET", "/v1/items/{id}")
+	req.Path("id", in.ID)
+	var out *item
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// UpdateItem calls PUT /v1/items/{id}.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) UpdateItem(ctx context.Context, in updateRequest) (*updatedItem, error) {
+	req := c.c.NewRequest("PUT", "/v1/items/{id}")
+	req.Path("id", in.ID)
+	req.Header("X-Item-Version", strconv.FormatInt(int64(in.Version), 10))
+	if in.Notify {
+		req.Query("notify", strconv.FormatBool(in.Notify))
+	}
+	for _, v := range in.Tags {
+		req.Query("tag", v)
+	}
+	req.Body(in.Item)
+	var out *updatedItem
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// AddNote calls POST /v1/items/{id}/notes.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) AddNote(ctx context.Context, in noteRequest) (*note, error) {
+	req := c.c.NewRequest("POST", "/v1/items/{id}/notes")
+	req.Path("id", in.ItemID)
+	req.Form("text", in.Text)
+	if in.Pinned {
+		req.Form("pinned", strconv.FormatBool(in.Pinned))
+	}
+	var out *note
+	if err := c.c.Do(ctx, req, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// DeleteItem calls DELETE /v1/items/{id}.
+// Failed calls return *sdesc.ClientError.
+func (c *HandlerClient) DeleteItem(ctx context.Context, in getRequest) error {
+	req := c.c.NewRequest("DELETE", "/v1/items/{id}")
+	req.Path("id", in.ID)
+	return c.c.Do(ctx, req, nil)
+}
@@ -0,0 +1,86 @@
+package test3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/utrack/pontoon/sdesc"
+)
+
+// testAuth accepts the bearer token "token" and the API key "key".
+var testAuth = sdesc.AuthenticatorFunc(func(r *http.Request, reqs []sdesc.SecurityRequirement) (context.Context, error) {
+	for _, req := range reqs {
+		switch {
+		case req.Scheme == "jwt" && r.Header.Get("Authorization") == "Bearer token":
+			return nil, nil
+		case req.Scheme == "apiKey" && r.Header.Get("X-API-Key") == "key":
+			return nil, nil
+		}
+	}
+	return nil, errors.New("not authenticated")
+})
+
+// TestCompiledBindings checks that the generated bindings
+// serve the same responses as the reflective Router.
+func TestCompiledBindings(t *testing.T) {
+	reflective := sdesc.NewRouter(sdesc.WithAuthenticator(testAuth))
+	reflective.Register(Handler{})
+
+	compiled := http.NewServeMux()
+	Handler{}.RegisterHTTPHandlers(compiled, sdesc.WithAuthenticator(testAuth))
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		header   http.Header
+		wantCode int
+	}{
+		{"public list", http.MethodGet, "/v1/items", nil, http.StatusOK},
+		{"list as XML", http.MethodGet, "/v1/items", http.Header{"Accept": {"application/xml"}}, http.StatusOK},
+		{"unacceptable", http.MethodGet, "/v1/items", http.Header{"Accept": {"text/csv"}}, http.StatusNotAcceptable},
+		{"anonymous get", http.MethodGet, "/v1/items/1", nil, http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, "/v1/items/1", http.Header{"Authorization": {"Bearer other"}}, http.StatusUnauthorized},
+		{"get", http.MethodGet, "/v1/items/1", http.Header{"Authorization": {"Bearer token"}}, http.StatusNotFound},
+		{"anonymous delete", http.MethodDelete, "/v1/items/1", nil, http.StatusUnauthorized},
+		{"delete by API key", http.MethodDelete, "/v1/items/1", http.Header{"X-API-Key": {"key"}}, http.StatusOK},
+		{"unknown route", http.MethodPost, "/v1/items", nil, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serve := func(h http.Handler) *httptest.ResponseRecorder {
+				r := httptest.NewRequest(tt.method, tt.target, nil)
+				for k, vs := range tt.header {
+					for _, v := range vs {
+						r.Header.Add(k, v)
+					}
+				}
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				return w
+			}
+			want, got := serve(reflective), serve(compiled)
+
+			if tt.wantCode != 0 && want.Code != tt.wantCode {
+				t.Errorf("Router: got status %v, want %v", want.Code, tt.wantCode)
+			}
+			if got.Code != want.Code {
+				t.Errorf("got status %v, Router's is %v", got.Code, want.Code)
+			}
+			if !reflect.DeepEqual(got.Header(), want.Header()) {
+				t.Errorf("got headers %v, Router's are %v", got.Header(), want.Header())
+			}
+			gotBody, _ := io.ReadAll(got.Body)
+			wantBody, _ := io.ReadAll(want.Body)
+			if strings.TrimSpace(string(gotBody)) != strings.TrimSpace(string(wantBody)) {
+				t.Errorf("got body %s, Router's is %s", gotBody, wantBody)
+			}
+		})
+	}
+}
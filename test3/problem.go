@@ -0,0 +1,118 @@
+package test3
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/utrack/pontoon/sdesc"
+)
+
+// Handler reports its errors as RFC 7807 problems,
+// requires authentication and speaks both JSON and XML.
+type Handler struct{}
+
+var _ sdesc.Service = &Handler{}
+
+type getRequest struct {
+	ID string `in:"path=id;required"`
+}
+
+type item struct {
+	ID string `json:"id" xml:"id" example:"item-1"`
+}
+
+type updateRequest struct {
+	ID string `in:"path=id;required"`
+
+	// Version is the item's version the update is based on.
+	Version int `in:"header=X-Item-Version;required" validate:"min=1"`
+
+	// Notify tells the item's watchers about the update.
+	Notify bool `in:"query=notify;default=true"`
+
+	// Tags replace the item's tags.
+	Tags []string `in:"query=tag"`
+
+	Item itemUpdate `in:"body=json"`
+}
+
+type itemUpdate struct {
+	Name string `json:"name"`
+}
+
+// updatedItem is an item after the update.
+type updatedItem struct {
+	ID       string   `json:"id" xml:"id" example:"item-1"`
+	Name     string   `json:"name" xml:"name"`
+	Version  int      `json:"version" xml:"version"`
+	Tags     []string `json:"tags" xml:"tag"`
+	Notified bool     `json:"notified" xml:"notified"`
+}
+
+type noteRequest struct {
+	ItemID string `in:"path=id;required"`
+	Text   string `in:"form=text;required"`
+	Pinned bool   `in:"form=pinned"`
+}
+
+// note is a remark on an item.
+type note struct {
+	ItemID string `json:"item_id" xml:"item_id"`
+	Text   string `json:"text" xml:"text"`
+	Pinned bool   `json:"pinned" xml:"pinned"`
+}
+
+// getItem returns an item by its ID.
+func (h Handler) getItem(r *http.Request, req getRequest) (*item, error) {
+	return nil, sdesc.NotFound("no such item")
+}
+
+// listItems returns every item; it's public.
+func (h Handler) listItems(r *http.Request) ([]item, error) {
+	return nil, nil
+}
+
+// deleteItem removes an item by its ID.
+func (h Handler) deleteItem(ctx context.Context, req getRequest) error {
+	if h := sdesc.ResponseHeaderFromContext(ctx); h != nil {
+		h.Set("X-Deleted-Item", req.ID)
+	}
+	return nil
+}
+
+// updateItem replaces an item's name and tags.
+func (h Handler) updateItem(r *http.Request, req updateRequest) (*updatedItem, error) {
+	return &updatedItem{
+		ID:       req.ID,
+		Name:     req.Item.Name,
+		Version:  req.Version + 1,
+		Tags:     req.Tags,
+		Notified: req.Notify,
+	}, nil
+}
+
+// addNote adds a note to an item.
+func (h Handler) addNote(r *http.Request, req noteRequest) (*note, error) {
+	return &note{ItemID: req.ItemID, Text: req.Text, Pinned: req.Pinned}, nil
+}
+
+func (h Handler) ServiceOptions() []sdesc.ServiceOption {
+	return []sdesc.ServiceOption{
+		sdesc.WithProblemDetails(),
+		sdesc.WithMediaTypes(sdesc.MediaTypeJSON, sdesc.MediaTypeXML),
+		sdesc.WithSecurityScheme("jwt", sdesc.BearerAuth("JWT")),
+		sdesc.WithSecurityScheme("apiKey", sdesc.APIKeyHeader("X-API-Key")),
+		sdesc.WithSecurity("jwt", "items:read"),
+	}
+}
+
+func (h Handler) RegisterHTTP(mux sdesc.HTTPRouter) {
+	mux.MethodFunc(http.MethodGet, "/v1/items", h.listItems,
+		sdesc.WithoutSecurity())
+	sdesc.Handle(mux, http.MethodGet, "/v1/items/{id}", h.getItem)
+	mux.MethodFunc(http.MethodPut, "/v1/items/{id}", h.updateItem)
+	mux.MethodFunc(http.MethodPost, "/v1/items/{id}/notes", h.addNote)
+	mux.MethodFunc(http.MethodDelete, "/v1/items/{id}", h.deleteItem,
+		sdesc.WithRouteSecurity("jwt", "items:write"),
+		sdesc.WithRouteSecurity("apiKey"))
+}